@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptAll  bool
+	adoptMove bool
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [path]",
+	Short: "Register existing git worktrees that are not managed yet",
+	Long: `Register worktrees that were created with plain git so that switch, remove
+and the other commands can work with them.
+
+Without arguments the worktree in the current directory is adopted; pass a
+path to adopt another one, or --all to adopt every unmanaged worktree of the
+current repository. Worktrees with a detached HEAD are registered under a
+generated name of the form detached-<commit>.
+
+With --move the worktrees are also moved into the managed directory layout
+(<worktree-dir>/<owner>/<repo>/<branch>) using git worktree move.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if adoptAll && len(args) > 0 {
+			fmt.Println("Error: pass either a path or --all")
+			return
+		}
+
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			fmt.Printf("Error resolving path: %v\n", err)
+			return
+		}
+
+		remoteURL, err := gitOutput(dir, "config", "--get", "remote.origin.url")
+		if err != nil {
+			fmt.Printf("Error getting remote origin URL: %v\n", err)
+			return
+		}
+		orgRepo := ParseRemoteURL(remoteURL)
+		if orgRepo == "" {
+			fmt.Printf("Could not parse organization/username and repository name from remote URL: %s\n", remoteURL)
+			return
+		}
+		repoPath, _ := mainCheckoutPath(dir)
+
+		worktrees, err := listGitWorktrees(dir)
+		if err != nil {
+			fmt.Printf("Error listing git worktrees: %v\n", err)
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+		managed := make(map[string]bool)
+		for _, entry := range stateManager.ListWorktrees() {
+			managed[entry.Path] = true
+		}
+
+		// Pick the worktrees to adopt; the first one git lists is the main checkout
+		var targets []gitWorktree
+		if adoptAll {
+			for i, wt := range worktrees {
+				if i == 0 || wt.Bare || wt.Prunable || managed[wt.Path] {
+					continue
+				}
+				targets = append(targets, wt)
+			}
+			if len(targets) == 0 {
+				fmt.Println("No unmanaged worktrees to adopt")
+				return
+			}
+		} else {
+			toplevel, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+			if err != nil {
+				fmt.Printf("Error: '%s' is not inside a git worktree\n", dir)
+				return
+			}
+			for i, wt := range worktrees {
+				if wt.Path != toplevel {
+					continue
+				}
+				switch {
+				case i == 0:
+					fmt.Printf("Error: '%s' is the main checkout of %s\n", toplevel, orgRepo)
+					return
+				case managed[wt.Path]:
+					fmt.Printf("Worktree at '%s' is already managed\n", toplevel)
+					return
+				}
+				targets = append(targets, wt)
+			}
+			if len(targets) == 0 {
+				fmt.Printf("Error: git does not list '%s' as a worktree\n", toplevel)
+				return
+			}
+		}
+
+		adopted := 0
+		for _, wt := range targets {
+			if err := adoptWorktree(stateManager, wt, orgRepo, remoteURL, repoPath); err != nil {
+				fmt.Printf("Error adopting '%s': %v\n", wt.Path, err)
+				continue
+			}
+			adopted++
+		}
+		if len(targets) > 1 {
+			fmt.Printf("\nAdopted %d of %d worktree(s)\n", adopted, len(targets))
+		}
+	},
+}
+
+func init() {
+	defaultWorktreeDir := GetDefaultWorktreeDir()
+	if envVar := os.Getenv("GIT_WORKTREE_MANAGER_DIR"); envVar != "" {
+		defaultWorktreeDir = envVar
+	}
+	adoptCmd.Flags().StringVarP(&commonWorktreeDir, "worktree-dir", "w", defaultWorktreeDir, "Base directory to move adopted worktrees into")
+	adoptCmd.Flags().BoolVarP(&adoptAll, "all", "a", false, "Adopt every unmanaged worktree of the current repository")
+	adoptCmd.Flags().BoolVarP(&adoptMove, "move", "m", false, "Move adopted worktrees into the managed directory layout")
+	rootCmd.AddCommand(adoptCmd)
+}
+
+// adoptWorktree registers the git worktree wt, moving it into the managed
+// layout first when --move is set
+func adoptWorktree(stateManager *state.StateManager, wt gitWorktree, orgRepo, remoteURL, repoPath string) error {
+	name := wt.Branch
+	if name == "" || strings.HasPrefix(name, "detached") {
+		short, err := gitOutput(wt.Path, "rev-parse", "--short", "HEAD")
+		if err != nil {
+			return fmt.Errorf("cannot read HEAD: %w", err)
+		}
+		name = "detached-" + short
+	}
+
+	id := filepath.Join(orgRepo, name)
+	for _, entry := range stateManager.ListWorktrees() {
+		if entry.ID == id {
+			return fmt.Errorf("'%s' is already registered at '%s'", id, entry.Path)
+		}
+	}
+
+	path := wt.Path
+	if adoptMove {
+		target := filepath.Join(commonWorktreeDir, orgRepo, name)
+		if target != path {
+			if wt.Locked {
+				return fmt.Errorf("worktree is locked; unlock it with git worktree unlock to move it")
+			}
+			if _, err := os.Stat(target); err == nil {
+				return fmt.Errorf("'%s' already exists", target)
+			}
+			if err := fileChange("create", filepath.Dir(target), func() error { return os.MkdirAll(filepath.Dir(target), 0755) }); err != nil {
+				return err
+			}
+			planFile("move", wt.Path+" -> "+target)
+			if out, err := gitChange(repoPath, "worktree", "move", wt.Path, target); err != nil {
+				return fmt.Errorf("git worktree move failed: %v: %s", err, out)
+			}
+			printDone("Moved '%s' to '%s'\n", path, target)
+			path = target
+		}
+	}
+
+	if err := stateManager.AddWorktree(path, orgRepo, name, remoteURL, repoPath); err != nil {
+		return err
+	}
+	printDone("Adopted worktree for '%s' at '%s'\n", name, path)
+	return nil
+}
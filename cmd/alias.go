@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias [branch-name] [nickname]...",
+	Short: "Give a worktree nicknames to refer to it by",
+	Long: `Give the worktree of a branch in the current repository nicknames. Every
+command that takes a branch name also accepts its nicknames. Without nicknames,
+the worktree's current nicknames are shown.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		orgRepo, err := currentRepoName()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		entry, err := resolveWorktree(stateManager, orgRepo, args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if len(args) == 1 {
+			if len(entry.Aliases) == 0 {
+				fmt.Printf("Worktree for branch '%s' has no nicknames\n", entry.BranchName)
+				return
+			}
+			fmt.Println(strings.Join(entry.Aliases, "\n"))
+			return
+		}
+
+		aliases := entry.Aliases
+		for _, alias := range args[1:] {
+			if entry.HasAlias(alias) || containsString(aliases, alias) {
+				continue
+			}
+			// A nickname must refer to a single worktree
+			for _, other := range stateManager.ListWorktreesByRepo(orgRepo) {
+				if other.ID != entry.ID && (other.BranchName == alias || other.HasAlias(alias)) {
+					fmt.Printf("Error: '%s' already refers to the worktree for branch '%s'\n", alias, other.BranchName)
+					return
+				}
+			}
+			aliases = append(aliases, alias)
+		}
+
+		if err := stateManager.SetAliases(orgRepo, entry.BranchName, aliases); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		printDone("Worktree for branch '%s' can be referred to as: %s\n", entry.BranchName, strings.Join(aliases, ", "))
+	},
+}
+
+var unaliasCmd = &cobra.Command{
+	Use:   "unalias [nickname]...",
+	Short: "Remove nicknames of worktrees",
+	Args:  cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return aliasCompletions(args), cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		orgRepo, err := currentRepoName()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		for _, alias := range args {
+			found := false
+			for _, entry := range stateManager.ListWorktreesByRepo(orgRepo) {
+				if !entry.HasAlias(alias) {
+					continue
+				}
+				found = true
+				var aliases []string
+				for _, a := range entry.Aliases {
+					if a != alias {
+						aliases = append(aliases, a)
+					}
+				}
+				if err := stateManager.SetAliases(orgRepo, entry.BranchName, aliases); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				printDone("Removed nickname '%s' of the worktree for branch '%s'\n", alias, entry.BranchName)
+			}
+			if !found {
+				fmt.Printf("No worktree is nicknamed '%s'\n", alias)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(unaliasCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var checkSelector worktreeSelector
+
+var checkCmd = &cobra.Command{
+	Use:   "check [branch-name]",
+	Short: "Report work that would be lost by removing worktrees",
+	Long: `Analyse worktrees for work that removing them (and their branch) would destroy:
+uncommitted changes, untracked files, ignored files matching a precious pattern
+(see 'config set precious'), commits that are not on any remote, stashes created
+on the branch and rebases or merges in progress.
+
+Without a branch name every managed worktree of the current repository is
+checked. Exits with status 1 if anything would be lost.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkSelector.resolveRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		entries := checkSelector.selectFrom(stateManager)
+		if len(args) > 0 {
+			entry, err := matchWorktree(args[0], entries, checkSelector.all)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			entries = []state.WorktreeEntry{entry}
+		}
+		if len(entries) == 0 {
+			fmt.Println("No managed worktrees")
+			return
+		}
+
+		unsafe := 0
+		for _, group := range groupByRepo(entries) {
+			gitRoot, err := group.checkoutDir()
+			if err != nil {
+				fmt.Printf("Error checking %s: %v\n", group.repo, err)
+				continue
+			}
+			sortListRows(group.rows, "branch")
+			for _, row := range group.rows {
+				report, err := checkWorktree(row.entry, gitRoot, cfg.ForRepo(group.repo).Precious)
+				if err != nil {
+					fmt.Printf("Error checking '%s': %v\n", row.entry.BranchName, err)
+					unsafe++
+					continue
+				}
+				report.print()
+				if blockers := report.blockers(true); len(blockers) > 0 {
+					fmt.Printf("  Would lose: %s\n", strings.Join(blockers, ", "))
+					unsafe++
+				}
+				fmt.Println()
+			}
+		}
+
+		if unsafe > 0 {
+			fmt.Printf("%d of %d worktree(s) have work that would be lost\n", unsafe, len(entries))
+			os.Exit(1)
+		}
+		fmt.Printf("All %d worktree(s) can be removed safely\n", len(entries))
+	},
+}
+
+func init() {
+	checkCmd.Flags().StringVar(&checkSelector.repo, "repo", "", "Repository to check (owner/repo); defaults to the current repository")
+	checkCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	checkCmd.Flags().BoolVarP(&checkSelector.all, "all", "a", false, "Check worktrees of all repositories")
+	rootCmd.AddCommand(checkCmd)
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var cleanupYes bool
+
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Clean up stale worktree entries from state",
 	Long: `Remove entries for worktrees that no longer exist on disk.
-This helps keep the worktree state file clean and accurate.`,
+This helps keep the worktree state file clean and accurate.
+Pinned worktrees are kept even when their directory is gone.`,
 	Aliases: []string{"clean"},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Initialize state manager
@@ -21,6 +24,21 @@ This helps keep the worktree state file clean and accurate.`,
 			return
 		}
 
+		// Show what would be removed and ask before forgetting it
+		stale := 0
+		for _, entry := range stateManager.StaleEntries() {
+			if entry.Pinned {
+				fmt.Printf("Keeping pinned worktree '%s' (%s); its directory is gone\n", entry.BranchName, entry.GitRepo)
+				continue
+			}
+			fmt.Printf("Stale: '%s' (%s) at '%s'\n", entry.BranchName, entry.GitRepo, entry.Path)
+			stale++
+		}
+		if stale > 0 && !cleanupYes && !confirm(fmt.Sprintf("Remove %d stale entries?", stale)) {
+			fmt.Println("Aborted")
+			return
+		}
+
 		// Get worktrees before cleanup for comparison
 		beforeCount := len(stateManager.ListWorktrees())
 
@@ -46,6 +64,8 @@ This helps keep the worktree state file clean and accurate.`,
 }
 
 func init() {
+	cleanupCmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "Do not ask for confirmation")
+
 	// Add cleanup command to root
 	rootCmd.AddCommand(cleanupCmd)
 }
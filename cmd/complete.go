@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// Completion candidates are "value\tdescription"; shells that support it show the description
+
+// completeWorktree completes the branch name of a registered worktree as the first argument
+func completeWorktree(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return worktreeCompletions(nil, false), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktrees completes any number of registered worktrees, skipping those already given
+func completeWorktrees(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return worktreeCompletions(args, false), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSwitchTarget completes registered worktrees; outside a repository
+// their fully qualified IDs (owner/repo/branch), which switch accepts there.
+// The shortcuts '-' and '@main' are offered as well.
+func completeSwitchTarget(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := []string{"-\tprevious worktree"}
+	if _, err := mainCheckoutPath(""); err == nil {
+		completions = append(completions, "@main\tprimary checkout")
+	}
+	return append(completions, worktreeCompletions(nil, true)...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// worktreeCompletions lists the worktrees of the current repository with their
+// path and age. Outside a repository it lists the IDs of all worktrees if
+// qualified is set, and nothing otherwise.
+func worktreeCompletions(exclude []string, qualified bool) []string {
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil
+	}
+
+	var entries []state.WorktreeEntry
+	if repo, err := currentRepoName(); err == nil {
+		entries = stateManager.ListWorktreesByRepo(repo)
+		qualified = false
+	} else if qualified {
+		entries = stateManager.ListWorktrees()
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	var completions []string
+	for _, entry := range entries {
+		name := entry.BranchName
+		if qualified {
+			name = entry.ID
+		}
+		if skip[name] {
+			continue
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%s, created %s", name, entry.Path, ago(entry.CreatedAt)))
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// aliasCompletions lists the nicknames of the worktrees of the current repository
+func aliasCompletions(exclude []string) []string {
+	repo, err := currentRepoName()
+	if err != nil {
+		return nil
+	}
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, entry := range stateManager.ListWorktreesByRepo(repo) {
+		for _, alias := range entry.Aliases {
+			if !containsString(exclude, alias) {
+				completions = append(completions, alias+"\t"+entry.BranchName)
+			}
+		}
+	}
+	return completions
+}
+
+// completeNewBranch completes local and remote branches that are not checked
+// out in any worktree yet, most recent first within each kind
+func completeNewBranch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := make(map[string]bool)
+	if worktrees, err := listGitWorktrees(""); err == nil {
+		for _, wt := range worktrees {
+			seen[wt.Branch] = true
+		}
+	}
+
+	out, err := gitOutput("", "for-each-ref", "--sort=-committerdate", "--format=%(refname)%09%(committerdate:unix)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var local, remote []string
+	for _, line := range splitLines(out) {
+		ref, date, _ := strings.Cut(line, "\t")
+		kind := "local branch"
+		name, isLocal := strings.CutPrefix(ref, "refs/heads/")
+		if !isLocal {
+			// refs/remotes/<remote>/<branch>; git worktree add creates a tracking branch for it
+			remoteName, branch, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+			if !ok || branch == "HEAD" {
+				continue
+			}
+			name, kind = branch, "on "+remoteName
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		completion := name + "\t" + kind
+		if seconds, err := strconv.ParseInt(date, 10, 64); err == nil {
+			completion += ", last commit " + ago(time.Unix(seconds, 0))
+		}
+		if isLocal {
+			local = append(local, completion)
+		} else {
+			remote = append(remote, completion)
+		}
+	}
+	return append(local, remote...), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeLabels completes the labels used by registered worktrees
+func completeLabels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	counts := make(map[string]int)
+	for _, entry := range stateManager.ListWorktrees() {
+		for _, label := range entry.Labels {
+			counts[label]++
+		}
+	}
+	return countCompletions(counts), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepos completes the repositories that have registered worktrees
+func completeRepos(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	counts := make(map[string]int)
+	for _, entry := range stateManager.ListWorktrees() {
+		counts[entry.GitRepo]++
+	}
+	return countCompletions(counts), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKey completes the keys of 'config set', 'config get' and 'config unset'
+func completeConfigKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for name, key := range configKeys {
+		completions = append(completions, name+"\t"+key.description)
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTrashItem completes the IDs of items in the trash
+func completeTrashItem(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	items, _ := listTrash(stateManager)
+	var completions []string
+	for _, item := range items {
+		completions = append(completions, fmt.Sprintf("%s\t%s (%s), trashed %s", item.id, item.Entry.BranchName, item.Entry.GitRepo, ago(item.TrashedAt)))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// countCompletions turns counted values into completions described by their count
+func countCompletions(counts map[string]int) []string {
+	var completions []string
+	for value, n := range counts {
+		completions = append(completions, fmt.Sprintf("%s\t%d worktree(s)", value, n))
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// ago describes how long ago t was, e.g. "3d ago"
+func ago(t time.Time) string {
+	age := formatAge(time.Since(t))
+	if age == "now" {
+		return "just now"
+	}
+	return age + " ago"
+}
@@ -2,11 +2,182 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/garymjr/git-worktree-manager/pkg/state"
 	"github.com/spf13/cobra"
 )
 
+var configRepo string
+
+// configKey describes a setting that can be changed with 'config set'
+type configKey struct {
+	description string
+	get         func(rc *state.RepoConfig) string
+	set         func(rc *state.RepoConfig, value string) error // An empty value unsets the key
+}
+
+// configKeys are the settings available through 'config set', 'config get' and 'config unset'
+var configKeys = map[string]configKey{
+	"ttl": {
+		description: "Expire worktrees not accessed for this long (e.g. 30d)",
+		get:         func(rc *state.RepoConfig) string { return rc.TTL },
+		set: func(rc *state.RepoConfig, value string) error {
+			if value != "" {
+				if _, err := parseAge(value); err != nil {
+					return err
+				}
+			}
+			rc.TTL = value
+			return nil
+		},
+	},
+	"max_worktrees": {
+		description: "Maximum number of worktrees per repository checked by create",
+		get: func(rc *state.RepoConfig) string {
+			if rc.MaxWorktrees == 0 {
+				return ""
+			}
+			return strconv.Itoa(rc.MaxWorktrees)
+		},
+		set: func(rc *state.RepoConfig, value string) error {
+			if value == "" {
+				rc.MaxWorktrees = 0
+				return nil
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("expected a positive number")
+			}
+			rc.MaxWorktrees = n
+			return nil
+		},
+	},
+	"max_size_gb": {
+		description: "Maximum total size of a repository's worktrees in gigabytes, checked by create",
+		get: func(rc *state.RepoConfig) string {
+			if rc.MaxSizeGB == 0 {
+				return ""
+			}
+			return strconv.FormatFloat(rc.MaxSizeGB, 'f', -1, 64)
+		},
+		set: func(rc *state.RepoConfig, value string) error {
+			if value == "" {
+				rc.MaxSizeGB = 0
+				return nil
+			}
+			gb, err := strconv.ParseFloat(value, 64)
+			if err != nil || gb <= 0 {
+				return fmt.Errorf("expected a positive number")
+			}
+			rc.MaxSizeGB = gb
+			return nil
+		},
+	},
+	"quota_mode": {
+		description: "What create does when a quota is exceeded: warn or block",
+		get:         func(rc *state.RepoConfig) string { return rc.QuotaMode },
+		set: func(rc *state.RepoConfig, value string) error {
+			if value != "" && value != "warn" && value != "block" {
+				return fmt.Errorf("expected warn or block")
+			}
+			rc.QuotaMode = value
+			return nil
+		},
+	},
+	"snapshot_keep": {
+		description: "Number of snapshots kept per branch (default " + strconv.Itoa(defaultSnapshotKeep) + ")",
+		get: func(rc *state.RepoConfig) string {
+			if rc.SnapshotKeep == 0 {
+				return ""
+			}
+			return strconv.Itoa(rc.SnapshotKeep)
+		},
+		set: func(rc *state.RepoConfig, value string) error {
+			if value == "" {
+				rc.SnapshotKeep = 0
+				return nil
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("expected a positive number")
+			}
+			rc.SnapshotKeep = n
+			return nil
+		},
+	},
+	"snapshot_ttl": {
+		description: "Delete snapshots older than this (e.g. 30d)",
+		get:         func(rc *state.RepoConfig) string { return rc.SnapshotTTL },
+		set: func(rc *state.RepoConfig, value string) error {
+			if value != "" {
+				if _, err := parseAge(value); err != nil {
+					return err
+				}
+			}
+			rc.SnapshotTTL = value
+			return nil
+		},
+	},
+	"precious": {
+		description: "Comma-separated patterns of ignored files that block removal (default " + strings.Join(defaultPreciousPatterns, ",") + ")",
+		get:         func(rc *state.RepoConfig) string { return strings.Join(rc.Precious, ",") },
+		set: func(rc *state.RepoConfig, value string) error {
+			patterns, err := splitPatterns(value)
+			if err != nil {
+				return err
+			}
+			rc.Precious = patterns
+			return nil
+		},
+	},
+	"protected": {
+		description: "Comma-separated branch patterns that are never deleted or pruned, besides the default branch (default " + strings.Join(defaultProtectedPatterns, ",") + ")",
+		get:         func(rc *state.RepoConfig) string { return strings.Join(rc.Protected, ",") },
+		set: func(rc *state.RepoConfig, value string) error {
+			patterns, err := splitPatterns(value)
+			if err != nil {
+				return err
+			}
+			rc.Protected = patterns
+			return nil
+		},
+	},
+	"prompt_format": {
+		description: "Template of the segment printed by prompt (default " + defaultPromptFormat + ")",
+		get:         func(rc *state.RepoConfig) string { return rc.PromptFormat },
+		set: func(rc *state.RepoConfig, value string) error {
+			if value != "" {
+				if _, err := parsePromptFormat(value); err != nil {
+					return err
+				}
+			}
+			rc.PromptFormat = value
+			return nil
+		},
+	},
+}
+
+// splitPatterns parses a comma-separated list of glob patterns
+func splitPatterns(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Show configuration and state information",
@@ -18,18 +189,164 @@ var configCmd = &cobra.Command{
 			fmt.Printf("Error initializing state manager: %v\n", err)
 			return
 		}
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
+			return
+		}
 
 		fmt.Println("Git Worktree Manager Configuration:")
 		fmt.Printf("State file location: %s\n", stateManager.GetConfigPath())
+		fmt.Printf("Config file location: %s\n", cfg.Path())
 		fmt.Printf("Total managed worktrees: %d\n", len(stateManager.ListWorktrees()))
-		
+
 		// Show fallback directory for legacy behavior
 		defaultDir := GetDefaultWorktreeDir()
 		fmt.Printf("Legacy default directory: %s\n", defaultDir)
+
+		printConfigSettings("Defaults", cfg.Defaults)
+		repos := make([]string, 0, len(cfg.Repos))
+		for repo := range cfg.Repos {
+			repos = append(repos, repo)
+		}
+		sort.Strings(repos)
+		for _, repo := range repos {
+			printConfigSettings(repo, cfg.Repos[repo])
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set [key] [value]",
+	Short:             "Change a setting, globally or for one repository with --repo",
+	Long:              "Change a setting, globally or for one repository with --repo.\n\nAvailable keys:\n" + configKeyHelp(),
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		updateConfig(args[0], args[1])
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset [key]",
+	Short:             "Remove a setting, globally or for one repository with --repo",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		updateConfig(args[0], "")
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Show the effective value of a setting, optionally for one repository with --repo",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, exists := configKeys[args[0]]
+		if !exists {
+			fmt.Printf("Unknown key '%s'. Available keys:\n%s", args[0], configKeyHelp())
+			return
+		}
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		effective := cfg.Defaults
+		if configRepo != "" {
+			effective = cfg.ForRepo(configRepo)
+		}
+		fmt.Println(key.get(&effective))
 	},
 }
 
 func init() {
+	for _, sub := range []*cobra.Command{configSetCmd, configUnsetCmd, configGetCmd} {
+		sub.Flags().StringVar(&configRepo, "repo", "", "Repository the setting applies to (owner/repo)")
+		sub.RegisterFlagCompletionFunc("repo", completeRepos)
+		configCmd.AddCommand(sub)
+	}
+
 	// Add config command to root
 	rootCmd.AddCommand(configCmd)
 }
+
+// updateConfig sets key to value (or unsets it when value is empty) and saves the configuration
+func updateConfig(name, value string) {
+	key, exists := configKeys[name]
+	if !exists {
+		fmt.Printf("Unknown key '%s'. Available keys:\n%s", name, configKeyHelp())
+		return
+	}
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		return
+	}
+
+	if configRepo == "" {
+		err = key.set(&cfg.Defaults, value)
+	} else {
+		rc := cfg.Repos[configRepo]
+		err = key.set(&rc, value)
+		if reflect.DeepEqual(rc, state.RepoConfig{}) {
+			delete(cfg.Repos, configRepo)
+		} else {
+			cfg.Repos[configRepo] = rc
+		}
+	}
+	if err != nil {
+		fmt.Printf("Invalid value for '%s': %v\n", name, err)
+		return
+	}
+
+	if err := cfg.Save(); err != nil {
+		fmt.Printf("Error saving configuration: %v\n", err)
+		return
+	}
+
+	scope := "globally"
+	if configRepo != "" {
+		scope = "for " + configRepo
+	}
+	if value == "" {
+		printDone("Unset '%s' %s\n", name, scope)
+	} else {
+		printDone("Set '%s' to '%s' %s\n", name, value, scope)
+	}
+}
+
+// printConfigSettings prints the keys that are set in rc under a heading
+func printConfigSettings(heading string, rc state.RepoConfig) {
+	var lines []string
+	for _, name := range configKeyNames() {
+		if value := configKeys[name].get(&rc); value != "" {
+			lines = append(lines, fmt.Sprintf("  %s = %s", name, value))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n%s\n", heading, strings.Join(lines, "\n"))
+}
+
+// configKeyNames returns the available setting names in alphabetical order
+func configKeyNames() []string {
+	names := make([]string, 0, len(configKeys))
+	for name := range configKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configKeyHelp lists the available settings, one per line
+func configKeyHelp() string {
+	var b strings.Builder
+	for _, name := range configKeyNames() {
+		fmt.Fprintf(&b, "  %-14s %s\n", name, configKeys[name].description)
+	}
+	return b.String()
+}
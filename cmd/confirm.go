@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// confirm asks a yes/no question on the terminal. When stdin is not a
+// terminal it returns false without waiting for an answer, so scripts have
+// to opt in explicitly (e.g. with --yes).
+func confirm(question string) bool {
+	if dryRun {
+		fmt.Printf("%s Assuming yes for the dry run\n", question)
+		return true
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Printf("%s Not a terminal; use --yes to proceed without confirmation\n", question)
+		return false
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// ask prompts for a line of input on the terminal and returns it trimmed
+func ask(question string) string {
+	fmt.Printf("%s ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -11,16 +12,20 @@ import (
 )
 
 var createBranch bool
+var createLabels []string
 
 func init() {
 	createCmd.Flags().BoolVarP(&createBranch, "create-branch", "b", false, "Create branch if it does not exist")
+	createCmd.Flags().StringSliceVarP(&createLabels, "label", "l", nil, "Label to attach to the worktree (repeatable)")
+	createCmd.RegisterFlagCompletionFunc("label", completeLabels)
 }
 
 var createCmd = &cobra.Command{
-	Use:     "create [branch-name]",
-	Short:   "Create a new worktree, optionally creating the branch if it does not exist",
-	Aliases: []string{"n", "new"},
-	Args:    cobra.ExactArgs(1),
+	Use:               "create [branch-name]",
+	Short:             "Create a new worktree, optionally creating the branch if it does not exist",
+	Aliases:           []string{"n", "new"},
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNewBranch,
 	Run: func(cmd *cobra.Command, args []string) {
 		branchName := args[0]
 
@@ -32,21 +37,6 @@ var createCmd = &cobra.Command{
 		}
 		gitRoot := strings.TrimSpace(string(gitRootBytes))
 
-		// Get the remote URL
-		remoteURLBytes, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
-		if err != nil {
-			fmt.Printf("Error getting remote origin URL: %v\n", err)
-			return
-		}
-		remoteURL := strings.TrimSpace(string(remoteURLBytes))
-
-		// Parse organization/username and repo name from remote URL
-		orgRepo := ParseRemoteURL(remoteURL)
-		if orgRepo == "" {
-			fmt.Printf("Could not parse organization/username and repository name from remote URL: %s\n", remoteURL)
-			return
-		}
-
 		// Initialize state manager
 		stateManager, err := state.NewStateManager()
 		if err != nil {
@@ -54,48 +44,110 @@ var createCmd = &cobra.Command{
 			return
 		}
 
-		// Construct the worktree path
-		worktreePath := filepath.Join(commonWorktreeDir, orgRepo, branchName)
-
-		// Add worktree to state
-		err = stateManager.AddWorktree(worktreePath, orgRepo, branchName, remoteURL)
-		if err != nil {
-			fmt.Printf("Error adding worktree to state: %v\n", err)
+		entry, err := createWorktree(stateManager, gitRoot, branchName, createBranch, createLabels)
+		if errors.Is(err, errQuotaExceeded) {
 			return
-		}
-
-		// Create the new worktree; only create the branch if requested
-		var cmdArgs []string
-		if createBranch {
-			cmdArgs = []string{"worktree", "add", "-b", branchName, worktreePath}
-		} else {
-			cmdArgs = []string{"worktree", "add", worktreePath, branchName}
-		}
-		cmdWorktree := exec.Command("git", cmdArgs...)
-		cmdWorktree.Dir = gitRoot
-		out, err := cmdWorktree.CombinedOutput()
-		if err != nil {
-			fmt.Printf("Error creating worktree at '%s': %v\nOutput: %s\n", worktreePath, err, out)
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
+		worktreePath := entry.Path
 
 		if createBranch {
-			fmt.Printf("Successfully created branch '%s' and worktree at '%s'\n", branchName, worktreePath)
+			printDone("Successfully created branch '%s' and worktree at '%s'\n", branchName, worktreePath)
 		} else {
-			fmt.Printf("Successfully created worktree for branch '%s' at '%s'\n", branchName, worktreePath)
+			printDone("Successfully created worktree for branch '%s' at '%s'\n", branchName, worktreePath)
 		}
 
 		// Switch to the new worktree
-		SwitchToWorktree(branchName, orgRepo, commonWorktreeDir, false)
+		SwitchToWorktree(branchName, entry.GitRepo, commonWorktreeDir, false)
 	},
 }
 
+// errQuotaExceeded is returned by createWorktree when a quota in block mode is
+// exceeded; the quotas have been reported already
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// createWorktree creates and registers a worktree for branchName below
+// commonWorktreeDir. gitRoot is any checkout of the repository. With
+// newBranch the branch is created too.
+func createWorktree(stateManager *state.StateManager, gitRoot, branchName string, newBranch bool, labels []string) (state.WorktreeEntry, error) {
+	// Get the remote URL
+	remoteURL, err := gitOutput(gitRoot, "config", "--get", "remote.origin.url")
+	if err != nil {
+		return state.WorktreeEntry{}, fmt.Errorf("getting remote origin URL: %w", err)
+	}
+
+	// Parse organization/username and repo name from remote URL
+	orgRepo := ParseRemoteURL(remoteURL)
+	if orgRepo == "" {
+		return state.WorktreeEntry{}, fmt.Errorf("could not parse organization/username and repository name from remote URL: %s", remoteURL)
+	}
+
+	// Remember the main checkout so the repository can be found from anywhere
+	repoPath, _ := mainCheckoutPath(gitRoot)
+
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		return state.WorktreeEntry{}, fmt.Errorf("loading configuration: %w", err)
+	}
+	if !checkQuota(stateManager, cfg, orgRepo) {
+		return state.WorktreeEntry{}, errQuotaExceeded
+	}
+
+	// Construct the worktree path
+	worktreePath := filepath.Join(commonWorktreeDir, orgRepo, branchName)
+
+	// Create the new worktree; only create the branch if requested
+	var cmdArgs []string
+	if newBranch {
+		cmdArgs = []string{"worktree", "add", "-b", branchName, worktreePath}
+	} else {
+		cmdArgs = []string{"worktree", "add", worktreePath, branchName}
+	}
+	planFile("create", worktreePath)
+	out, err := gitChange(gitRoot, cmdArgs...)
+	if err != nil {
+		return state.WorktreeEntry{}, fmt.Errorf("creating worktree at '%s': %v\nOutput: %s", worktreePath, err, out)
+	}
+
+	// Add worktree to state only once it exists; if that fails, the fresh
+	// worktree is taken away again so no unmanaged one is left behind
+	register := func() error {
+		if err := stateManager.AddWorktree(worktreePath, orgRepo, branchName, remoteURL, repoPath); err != nil {
+			return fmt.Errorf("adding worktree to state: %w", err)
+		}
+		if len(labels) > 0 {
+			if err := stateManager.SetLabels(orgRepo, branchName, labels); err != nil {
+				stateManager.RemoveWorktree(orgRepo, branchName)
+				return fmt.Errorf("labelling worktree: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := register(); err != nil {
+		gitOutput(gitRoot, "worktree", "remove", "--force", worktreePath)
+		if newBranch {
+			gitOutput(gitRoot, "branch", "-D", branchName)
+		}
+		return state.WorktreeEntry{}, err
+	}
+
+	entry, _ := stateManager.GetWorktree(orgRepo, branchName)
+	return entry, nil
+}
+
 // parseRemoteURL parses the remote URL to extract the organization/username and repository name.
-// It handles both HTTPS and SSH URLs.
+// It handles HTTPS and SSH URLs as well as other URL schemes and local paths,
+// which are used for remotes on the local filesystem.
 // Examples:
 //
 //	https://github.com/owner/repo.git -> owner/repo
 //	git@github.com:owner/repo.git -> owner/repo
+//	ssh://git@example.com/owner/repo.git -> owner/repo
+//	ssh://git@example.com/repo.git -> example.com/repo
+//	/srv/git/owner/repo.git -> owner/repo
+//	file:///srv/git/owner/repo.git -> owner/repo
 func ParseRemoteURL(url string) string {
 	// Remove .git suffix if present
 	url = strings.TrimSuffix(url, ".git")
@@ -112,6 +164,70 @@ func ParseRemoteURL(url string) string {
 		if len(parts) >= 2 {
 			return strings.Join(strings.Split(parts[1], "/"), "/")
 		}
+	} else if i := strings.Index(url, "://"); i >= 0 && url[:i] != "file" {
+		// Handle other schemes (ssh://, git://, ...); without an owner in the
+		// path the host takes its place, never the user name
+		_, path, _ := strings.Cut(url[i+3:], "/")
+		parts := pathComponents(path)
+		if len(parts) >= 2 {
+			return strings.Join(parts[len(parts)-2:], "/")
+		}
+		if host := ParseRemoteHost(url); len(parts) == 1 && host != "" {
+			return host + "/" + parts[0]
+		}
+	} else if strings.HasPrefix(url, "file://") || filepath.IsAbs(url) || strings.HasPrefix(url, ".") {
+		// Handle local paths
+		parts := pathComponents(filepath.ToSlash(strings.TrimPrefix(url, "file://")))
+		if len(parts) >= 2 {
+			return strings.Join(parts[len(parts)-2:], "/")
+		}
+	}
+
+	return ""
+}
+
+// pathComponents splits a slash-separated path into its non-empty components
+func pathComponents(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// ParseRemoteHost returns the host name of a remote URL, or "" if it has none.
+// Examples:
+//
+//	https://github.com/owner/repo.git -> github.com
+//	git@github.com:owner/repo.git -> github.com
+//	ssh://git@example.com:2222/owner/repo.git -> example.com
+func ParseRemoteHost(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		if url[:i] == "file" {
+			return ""
+		}
+		host := url[i+3:]
+		if j := strings.Index(host, "/"); j >= 0 {
+			host = host[:j]
+		}
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+		if j := strings.Index(host, ":"); j >= 0 {
+			host = host[:j]
+		}
+		return host
+	}
+
+	// scp-like syntax: [user@]host:path
+	if i := strings.Index(url, ":"); i > 0 && !strings.Contains(url[:i], "/") {
+		host := url[:i]
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+		return host
 	}
 
 	return ""
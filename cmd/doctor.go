@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// Problem classes reported by doctor
+const (
+	problemMissing        = "missing"         // Registered, but the directory is gone
+	problemBrokenLink     = "broken-link"     // The worktree's .git file points to a gitdir that does not exist
+	problemNotInGit       = "not-in-git"      // Registered and on disk, but git does not list the worktree
+	problemBranchMismatch = "branch-mismatch" // The worktree has a different branch checked out than registered
+	problemUnmanaged      = "unmanaged"       // Git lists the worktree, but it is not registered
+	problemPrunable       = "prunable"        // Git still has metadata for a worktree whose directory is gone
+	problemOrphan         = "orphan"          // A directory under the worktree directory that nothing owns
+)
+
+var doctorProblemKinds = []string{problemMissing, problemBrokenLink, problemNotInGit, problemBranchMismatch, problemUnmanaged, problemPrunable, problemOrphan}
+
+var (
+	doctorFix  bool
+	doctorOnly []string
+	doctorYes  bool
+)
+
+// doctorProblem is a single inconsistency between state, git and the filesystem
+type doctorProblem struct {
+	kind   string
+	repo   string
+	path   string
+	detail string
+	fix    string       // Description of the fix, empty if it has to be fixed by hand
+	apply  func() error // Performs the fix
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check state, git worktree metadata and the filesystem for inconsistencies",
+	Long: `Cross-check the worktrees registered in state, the worktrees git knows about
+and the directories on disk, and report every problem found.
+
+Problems are classified as:
+  missing          registered, but the directory no longer exists
+  broken-link      the worktree's .git file points to a gitdir that does not exist
+  not-in-git       registered and on disk, but git does not list the worktree
+  branch-mismatch  a different branch is checked out than the one registered
+  unmanaged        git lists the worktree, but it is not registered
+  prunable         git has metadata for a worktree whose directory is gone
+  orphan           a directory under the worktree directory that nothing owns
+
+By default only a report is printed. With --fix, each problem is repaired
+with git worktree repair, git worktree prune, by registering or unregistering
+the worktree, or by deleting the orphaned directory if it is empty. Orphaned
+directories with files in them are only reported. Use --only to limit
+checks and fixes to some problem classes.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, kind := range doctorOnly {
+			if !containsString(doctorProblemKinds, kind) {
+				fmt.Printf("Error: unknown problem class %q (available: %s)\n", kind, strings.Join(doctorProblemKinds, ", "))
+				return
+			}
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		problems := diagnose(stateManager, commonWorktreeDir)
+		if len(doctorOnly) > 0 {
+			filtered := problems[:0]
+			for _, problem := range problems {
+				if containsString(doctorOnly, problem.kind) {
+					filtered = append(filtered, problem)
+				}
+			}
+			problems = filtered
+		}
+
+		if len(problems) == 0 {
+			fmt.Println("No problems found")
+			return
+		}
+
+		t := newTable("PROBLEM", "REPO", "PATH", "DETAIL", "FIX")
+		t.flex = 2
+		for _, problem := range problems {
+			fix := problem.fix
+			if fix == "" {
+				fix = "manual"
+			}
+			t.addRow(problem.kind, problem.repo, problem.path, problem.detail, fix)
+		}
+		t.render(os.Stdout, nil, terminalWidth())
+
+		if !doctorFix {
+			fmt.Printf("\nFound %d problem(s). Run with --fix to repair them.\n", len(problems))
+			return
+		}
+
+		fmt.Println()
+		fixed := 0
+		for _, problem := range problems {
+			if problem.apply == nil {
+				continue
+			}
+			if problem.kind == problemOrphan && !doctorYes && !confirm(fmt.Sprintf("Delete '%s'?", problem.path)) {
+				fmt.Printf("Skipped '%s'\n", problem.path)
+				continue
+			}
+			if err := problem.apply(); err != nil {
+				fmt.Printf("Error fixing %s at '%s': %v\n", problem.kind, problem.path, err)
+				continue
+			}
+			fmt.Printf("Fixed %s: %s (%s)\n", problem.kind, problem.path, problem.fix)
+			fixed++
+		}
+		fmt.Printf("\nFixed %d of %d problem(s)\n", fixed, len(problems))
+	},
+}
+
+func init() {
+	defaultWorktreeDir := GetDefaultWorktreeDir()
+	if envVar := os.Getenv("GIT_WORKTREE_MANAGER_DIR"); envVar != "" {
+		defaultWorktreeDir = envVar
+	}
+	doctorCmd.Flags().StringVarP(&commonWorktreeDir, "worktree-dir", "w", defaultWorktreeDir, "Base directory to check for orphaned worktree directories")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the problems found")
+	doctorCmd.Flags().StringSliceVar(&doctorOnly, "only", nil, "Only check and fix these problem classes (comma-separated)")
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "Delete empty orphaned directories without asking")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// diagnose collects the problems of every repository with managed worktrees
+// and of the directories under worktreeDir
+func diagnose(stateManager *state.StateManager, worktreeDir string) []doctorProblem {
+	var problems []doctorProblem
+	known := make(map[string]bool) // Paths owned by state or git
+
+	for _, group := range groupByRepo(stateManager.ListWorktrees()) {
+		problems = append(problems, diagnoseRepo(stateManager, group, known)...)
+	}
+
+	problems = append(problems, findOrphans(worktreeDir, known)...)
+	return problems
+}
+
+// diagnoseRepo checks the managed worktrees of one repository against git and the filesystem
+func diagnoseRepo(stateManager *state.StateManager, group *repoGroup, known map[string]bool) []doctorProblem {
+	var problems []doctorProblem
+
+	mainPath := group.resolveMainPath()
+	if mainPath != "" {
+		if _, err := os.Stat(mainPath); err != nil {
+			mainPath = ""
+		}
+	}
+
+	// Without the main checkout git's view of the repository is unknown
+	var gitWorktrees map[string]gitWorktree
+	if mainPath != "" {
+		known[mainPath] = true
+		if worktrees, err := listGitWorktrees(mainPath); err == nil {
+			gitWorktrees = make(map[string]gitWorktree)
+			for _, wt := range worktrees {
+				gitWorktrees[wt.Path] = wt
+				known[wt.Path] = true
+			}
+		}
+	}
+
+	repair := func(path string) func() error {
+		return func() error {
+			if out, err := gitChange(mainPath, "worktree", "repair", path); err != nil {
+				return fmt.Errorf("%v: %s", err, out)
+			}
+			return nil
+		}
+	}
+	prune := func() error {
+		if out, err := gitChange(mainPath, "worktree", "prune"); err != nil {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+		return nil
+	}
+
+	var remoteURL string
+	managed := make(map[string]bool)
+	sortListRows(group.rows, "branch")
+	for _, row := range group.rows {
+		entry := row.entry
+		managed[entry.Path] = true
+		known[entry.Path] = true
+		if remoteURL == "" {
+			remoteURL = entry.RemoteURL
+		}
+		problem := doctorProblem{repo: group.repo, path: entry.Path}
+
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			problem.kind = problemMissing
+			problem.detail = fmt.Sprintf("branch '%s'", entry.BranchName)
+			problem.fix = "unregister"
+			problem.apply = func() error {
+				if err := stateManager.RemoveWorktree(entry.GitRepo, entry.BranchName); err != nil {
+					return err
+				}
+				if _, tracked := gitWorktrees[entry.Path]; tracked {
+					return prune()
+				}
+				return nil
+			}
+			problems = append(problems, problem)
+			continue
+		}
+
+		if gitdir, ok := readGitLink(entry.Path); ok {
+			if _, err := os.Stat(gitdir); err != nil {
+				problem.kind = problemBrokenLink
+				problem.detail = "points to " + gitdir
+				if adminDir := findWorktreeAdminDir(mainPath, entry.Path); adminDir != "" {
+					problem.fix = "relink and git worktree repair"
+					problem.apply = func() error {
+						link := "gitdir: " + adminDir + "\n"
+						gitFile := filepath.Join(entry.Path, ".git")
+						if err := fileChange("write", gitFile, func() error { return os.WriteFile(gitFile, []byte(link), 0644) }); err != nil {
+							return err
+						}
+						return repair(entry.Path)()
+					}
+				} else if mainPath != "" {
+					problem.detail += "; git has no metadata for it"
+				}
+				problems = append(problems, problem)
+				continue
+			}
+		}
+
+		if gitWorktrees == nil {
+			continue
+		}
+		wt, tracked := gitWorktrees[entry.Path]
+		if !tracked {
+			problem.kind = problemNotInGit
+			problem.detail = fmt.Sprintf("branch '%s'", entry.BranchName)
+			problem.fix = "git worktree repair"
+			problem.apply = repair(entry.Path)
+			problems = append(problems, problem)
+			continue
+		}
+		if wt.Branch != entry.BranchName && !strings.HasPrefix(wt.Branch, "detached") {
+			problem.kind = problemBranchMismatch
+			problem.detail = fmt.Sprintf("registered as '%s', has '%s' checked out", entry.BranchName, wt.Branch)
+			problem.fix = "re-register as '" + wt.Branch + "'"
+			actual := wt.Branch
+			problem.apply = func() error {
+				return stateManager.UpdateWorktree(entry.GitRepo, entry.BranchName, func(e *state.WorktreeEntry) {
+					e.BranchName = actual
+				})
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	// Worktrees git knows about that are not registered
+	paths := make([]string, 0, len(gitWorktrees))
+	for path := range gitWorktrees {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	prunable := false
+	for _, path := range paths {
+		wt := gitWorktrees[path]
+		if managed[path] || path == mainPath || wt.Bare {
+			continue
+		}
+		problem := doctorProblem{repo: group.repo, path: path}
+		switch {
+		case wt.Prunable:
+			problem.kind = problemPrunable
+			problem.detail = "directory is gone"
+			if !prunable {
+				// A single prune cleans up every stale entry of the repository
+				problem.fix = "git worktree prune"
+				problem.apply = prune
+				prunable = true
+			} else {
+				problem.fix = "git worktree prune"
+			}
+		case wt.Branch == "" || strings.HasPrefix(wt.Branch, "detached"):
+			problem.kind = problemUnmanaged
+			problem.detail = "detached HEAD"
+		default:
+			problem.kind = problemUnmanaged
+			problem.detail = fmt.Sprintf("branch '%s'", wt.Branch)
+			problem.fix = "register"
+			branch := wt.Branch
+			problem.apply = func() error {
+				return stateManager.AddWorktree(path, group.repo, branch, remoteURL, mainPath)
+			}
+		}
+		problems = append(problems, problem)
+	}
+
+	return problems
+}
+
+// findOrphans returns the top-most directories under worktreeDir that neither
+// state nor git accounts for. Directories holding nothing but other
+// directories are not reported themselves; their contents are.
+func findOrphans(worktreeDir string, known map[string]bool) []doctorProblem {
+	if _, err := os.Stat(worktreeDir); err != nil {
+		return nil
+	}
+
+	// Directories that lead to a known worktree must be kept
+	ancestors := make(map[string]bool)
+	for path := range known {
+		for dir := filepath.Dir(path); dir != worktreeDir && isWithinDir(dir, worktreeDir); dir = filepath.Dir(dir) {
+			ancestors[dir] = true
+		}
+	}
+
+	var problems []doctorProblem
+	filepath.WalkDir(worktreeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == worktreeDir {
+			return nil
+		}
+		if known[path] {
+			return filepath.SkipDir
+		}
+		if ancestors[path] {
+			return nil
+		}
+
+		// Only empty directories are deleted; anything else may hold work
+		problem := doctorProblem{kind: problemOrphan, path: path}
+		if gitdir, ok := readGitLink(path); ok {
+			if _, err := os.Stat(gitdir); err == nil {
+				problem.detail = fmt.Sprintf("worktree of an unknown repository (%s); bring it under management with 'adopt %s'", gitdir, path)
+			} else {
+				problem.detail = "worktree of a deleted repository (" + gitdir + "); check its files and delete it by hand"
+			}
+		} else if onlySubdirs(path) {
+			return nil // Look at what the directories inside hold
+		} else if isEmptyDir(path) {
+			problem.detail = "empty directory"
+			problem.fix = "delete directory"
+			problem.apply = func() error {
+				return fileChange("delete", path, func() error { return os.Remove(path) })
+			}
+		} else {
+			problem.detail = "not a worktree; check its files and delete it by hand"
+		}
+		problems = append(problems, problem)
+		return filepath.SkipDir
+	})
+	return problems
+}
+
+// readGitLink returns the gitdir a linked worktree's .git file points to.
+// ok is false when path has no .git file (for example a main checkout).
+func readGitLink(path string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "", false
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", false
+	}
+	gitdir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(path, gitdir)
+	}
+	return gitdir, true
+}
+
+// findWorktreeAdminDir returns the directory in the repository at mainPath
+// where git keeps the metadata of the linked worktree at path, or "" if the
+// repository has none for it
+func findWorktreeAdminDir(mainPath, path string) string {
+	if mainPath == "" {
+		return ""
+	}
+	commonDir, err := gitOutput(mainPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return ""
+	}
+	adminDirs, _ := filepath.Glob(filepath.Join(commonDir, "worktrees", "*"))
+	for _, adminDir := range adminDirs {
+		data, err := os.ReadFile(filepath.Join(adminDir, "gitdir"))
+		if err == nil && filepath.Dir(strings.TrimSpace(string(data))) == path {
+			return adminDir
+		}
+	}
+	return ""
+}
+
+// isEmptyDir reports whether path is a directory without entries
+func isEmptyDir(path string) bool {
+	entries, err := os.ReadDir(path)
+	return err == nil && len(entries) == 0
+}
+
+// onlySubdirs reports whether path holds directories and nothing else
+func onlySubdirs(path string) bool {
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) == 0 {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var duRepo string
+var duTop int
+
+// dirUsage is the size of a directory inside a worktree
+type dirUsage struct {
+	path string // Relative to the worktree root
+	size int64
+}
+
+// diskUsage is the size of a worktree on disk
+type diskUsage struct {
+	size    int64
+	ignored int64      // Part of size taken up by files git ignores
+	largest []dirUsage // Largest ignored directories, biggest first
+	err     error
+}
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Report disk usage of managed worktrees",
+	Long: `Measure every managed worktree and report its size, the largest directories
+ignored by git (typically build output and dependencies) and totals per repository.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		entries := stateManager.ListWorktrees()
+		if duRepo != "" {
+			entries = stateManager.ListWorktreesByRepo(duRepo)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No managed worktrees")
+			return
+		}
+
+		usage := measureWorktrees(entries)
+
+		var grandTotal int64
+		width := terminalWidth()
+		for i, group := range groupByRepo(entries) {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s\n", group.repo)
+
+			sortListRows(group.rows, "branch")
+			t := newTable("BRANCH", "SIZE", "IGNORED", "LARGEST IGNORED DIRECTORIES")
+			var total int64
+			for _, row := range group.rows {
+				u := usage[row.entry.Path]
+				if u.err != nil {
+					t.addRow(row.entry.BranchName, "-", "-", u.err.Error())
+					continue
+				}
+				var largest []string
+				for _, dir := range u.largest {
+					largest = append(largest, fmt.Sprintf("%s (%s)", dir.path, formatBytes(dir.size)))
+				}
+				t.addRow(row.entry.BranchName, formatBytes(u.size), formatBytes(u.ignored), strings.Join(largest, ", "))
+				total += u.size
+			}
+			markers := make([]string, len(group.rows))
+			for i := range markers {
+				markers[i] = "  "
+			}
+			t.render(os.Stdout, markers, width)
+			fmt.Printf("  Total: %s in %d worktree(s)\n", formatBytes(total), len(group.rows))
+			grandTotal += total
+		}
+
+		fmt.Printf("\nTotal: %s in %d worktree(s)\n", formatBytes(grandTotal), len(entries))
+	},
+}
+
+func init() {
+	duCmd.Flags().StringVar(&duRepo, "repo", "", "Only report worktrees of this repository (owner/repo)")
+	duCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	duCmd.Flags().IntVar(&duTop, "top", 3, "Number of largest ignored directories to show per worktree")
+	rootCmd.AddCommand(duCmd)
+}
+
+// measureWorktrees measures all entries concurrently, keyed by worktree path
+func measureWorktrees(entries []state.WorktreeEntry) map[string]diskUsage {
+	usage := make(map[string]diskUsage, len(entries))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			u := measureWorktree(path, duTop)
+			mu.Lock()
+			usage[path] = u
+			mu.Unlock()
+		}(entry.Path)
+	}
+	wg.Wait()
+
+	return usage
+}
+
+// measureWorktree sums the sizes of all files in the worktree at root and
+// finds the largest directories git ignores
+func measureWorktree(root string, top int) diskUsage {
+	if _, err := os.Stat(root); err != nil {
+		return diskUsage{err: fmt.Errorf("worktree not found")}
+	}
+
+	// Ask git which directories it ignores; a failure just means no breakdown
+	ignoredDirs := make(map[string]bool)
+	if output, err := gitOutput(root, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory"); err == nil {
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasSuffix(line, "/") {
+				ignoredDirs[filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(line, "/")))] = true
+			}
+		}
+	}
+
+	var u diskUsage
+	var ignored []dirUsage
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries rather than failing the whole worktree
+		}
+		if d.IsDir() && ignoredDirs[path] {
+			size := dirSize(path)
+			rel, _ := filepath.Rel(root, path)
+			ignored = append(ignored, dirUsage{path: rel, size: size})
+			u.size += size
+			u.ignored += size
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				u.size += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		u.err = err
+	}
+
+	sort.Slice(ignored, func(i, j int) bool { return ignored[i].size > ignored[j].size })
+	if len(ignored) > top {
+		ignored = ignored[:top]
+	}
+	u.largest = ignored
+	return u
+}
+
+// dirSize returns the total size of the regular files below path
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// formatBytes renders a byte count with a binary unit (e.g. "1.5 GiB")
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// Environment variables describing the worktree a command runs in
+const (
+	envWorktreePath = "GWM_WORKTREE_PATH"
+	envBranch       = "GWM_BRANCH"
+	envRepo         = "GWM_REPO"
+	envDepth        = "GWM_DEPTH" // Number of shells and commands started by git-worktree-manager this one runs in
+)
+
+var (
+	execSelector worktreeSelector
+	execParallel int
+	execMode     string
+	execFailFast bool
+	execLogDir   string
+)
+
+// execResult is the outcome of running the command in a single worktree
+type execResult struct {
+	entry    state.WorktreeEntry
+	exitCode int
+	err      error // Set when the command could not be started
+	skipped  bool  // Not run because of --fail-fast
+	duration time.Duration
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] [--] command [args...]",
+	Short: "Run a command in every matching managed worktree",
+	Long: `Run a command in each managed worktree of the current repository, or of the
+repositories and worktrees picked with --repo, --all, --label and --glob.
+
+The command runs with the worktree as its working directory and with
+` + envWorktreePath + `, ` + envBranch + ` and ` + envRepo + ` set in its environment.`,
+	Example: `  git-worktree-manager exec -- go test ./...
+  git-worktree-manager exec --all --glob 'feature/*' -j 8 -- git log -1 --oneline`,
+	Aliases: []string{"foreach"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if execMode != "prefixed" && execMode != "grouped" {
+			fmt.Printf("Error: unknown output mode %q (available: prefixed, grouped)\n", execMode)
+			return
+		}
+		if execParallel < 1 {
+			execParallel = 1
+		}
+		if err := execSelector.validate(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := execSelector.resolveRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		entries := execSelector.selectFrom(stateManager)
+		if len(entries) == 0 {
+			fmt.Println("No managed worktrees match")
+			return
+		}
+
+		if execLogDir != "" {
+			if err := fileChange("create", execLogDir, func() error { return os.MkdirAll(execLogDir, 0755) }); err != nil {
+				fmt.Printf("Error creating log directory '%s': %v\n", execLogDir, err)
+				return
+			}
+		}
+
+		results := runInWorktrees(entries, args)
+
+		fmt.Println()
+		fmt.Println("Summary:")
+		failed := 0
+		t := newTable("BRANCH", "REPO", "RESULT", "TIME")
+		for _, result := range results {
+			status := "ok"
+			duration := result.duration.Round(10 * time.Millisecond).String()
+			switch {
+			case result.skipped:
+				status = "skipped"
+				duration = "-"
+			case result.err != nil:
+				status = fmt.Sprintf("error: %v", result.err)
+				failed++
+			case result.exitCode != 0:
+				status = fmt.Sprintf("exit %d", result.exitCode)
+				failed++
+			}
+			t.addRow(result.entry.BranchName, result.entry.GitRepo, status, duration)
+		}
+		t.render(os.Stdout, nil, terminalWidth())
+
+		if failed > 0 {
+			fmt.Printf("%d of %d worktree(s) failed\n", failed, len(results))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().StringVar(&execSelector.repo, "repo", "", "Repository to run in (owner/repo); defaults to the current repository")
+	execCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	execCmd.Flags().BoolVarP(&execSelector.all, "all", "a", false, "Run in worktrees of all repositories")
+	execCmd.Flags().StringSliceVar(&execSelector.labels, "label", nil, "Only run in worktrees carrying this label (repeatable)")
+	execCmd.RegisterFlagCompletionFunc("label", completeLabels)
+	execCmd.Flags().StringSliceVarP(&execSelector.globs, "glob", "g", nil, "Only run in worktrees whose branch matches this pattern (repeatable)")
+	execCmd.Flags().IntVarP(&execParallel, "parallel", "j", 4, "Number of worktrees to run in at the same time")
+	execCmd.Flags().StringVarP(&execMode, "mode", "m", "prefixed", "Output mode: prefixed (interleaved lines tagged with the branch) or grouped (per worktree, once finished)")
+	execCmd.Flags().BoolVar(&execFailFast, "fail-fast", false, "Stop starting new commands and cancel running ones after the first failure")
+	execCmd.Flags().StringVar(&execLogDir, "log-dir", "", "Also write each worktree's output to a log file in this directory")
+	rootCmd.AddCommand(execCmd)
+}
+
+// worktreeEnv returns the environment for a command running in the worktree of entry
+func worktreeEnv(entry state.WorktreeEntry) []string {
+	return append(os.Environ(),
+		envWorktreePath+"="+entry.Path,
+		envBranch+"="+entry.BranchName,
+		envRepo+"="+entry.GitRepo,
+		envDepth+"="+strconv.Itoa(nestingDepth()+1),
+	)
+}
+
+// runInWorktrees runs args in every entry with at most execParallel commands at once.
+// Results are returned in the order of entries.
+func runInWorktrees(entries []state.WorktreeEntry, args []string) []execResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := make([]execResult, len(entries))
+	var stdoutMu sync.Mutex
+	sem := make(chan struct{}, execParallel)
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		results[i] = execResult{entry: entry}
+		wg.Add(1)
+		go func(i int, entry state.WorktreeEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				results[i].skipped = true
+				return
+			}
+
+			start := time.Now()
+			results[i].exitCode, results[i].err = runInWorktree(ctx, entry, args, &stdoutMu)
+			results[i].duration = time.Since(start)
+
+			if execFailFast && (results[i].exitCode != 0 || results[i].err != nil) {
+				cancel()
+			}
+		}(i, entry)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runInWorktree runs args in the worktree of entry and returns its exit code
+func runInWorktree(ctx context.Context, entry state.WorktreeEntry, args []string, stdoutMu *sync.Mutex) (int, error) {
+	if _, err := os.Stat(entry.Path); err != nil {
+		return -1, fmt.Errorf("worktree not found at '%s'", entry.Path)
+	}
+	if planRun(strings.Join(args, " "), entry.Path) {
+		return 0, nil
+	}
+
+	var out io.Writer
+	var prefixed *prefixWriter
+	var grouped bytes.Buffer
+	if execMode == "grouped" {
+		out = &grouped
+	} else {
+		prefixed = &prefixWriter{prefix: "[" + entry.BranchName + "] ", out: os.Stdout, mu: stdoutMu}
+		out = prefixed
+	}
+
+	if execLogDir != "" {
+		logName := strings.ReplaceAll(filepath.Join(entry.GitRepo, entry.BranchName), string(filepath.Separator), "_") + ".log"
+		logFile, err := os.Create(filepath.Join(execLogDir, logName))
+		if err != nil {
+			return -1, fmt.Errorf("creating log file: %w", err)
+		}
+		defer logFile.Close()
+		out = io.MultiWriter(out, logFile)
+	}
+
+	command := exec.CommandContext(ctx, args[0], args[1:]...)
+	command.Dir = entry.Path
+	command.Env = worktreeEnv(entry)
+	command.Stdout = out
+	command.Stderr = out
+	err := command.Run()
+
+	if prefixed != nil {
+		prefixed.Flush()
+	} else {
+		stdoutMu.Lock()
+		fmt.Printf("==> %s (%s)\n", entry.ID, entry.Path)
+		os.Stdout.Write(grouped.Bytes())
+		stdoutMu.Unlock()
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return -1, errors.New("cancelled")
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// prefixWriter writes complete lines to out, each preceded by prefix.
+// Writes are serialized through mu so lines from concurrent commands do not interleave.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	io.WriteString(w.out, w.prefix)
+	w.out.Write(line)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// expiryWarning is how long before expiry list starts warning about a worktree
+const expiryWarning = 7 * 24 * time.Hour
+
+// lastUsed returns when the worktree was last accessed, falling back to its creation time
+func lastUsed(entry state.WorktreeEntry) time.Time {
+	if entry.LastAccessed.IsZero() {
+		return entry.CreatedAt
+	}
+	return entry.LastAccessed
+}
+
+// repoTTL returns the configured time-to-live for worktrees of a repository,
+// or 0 if they do not expire
+func repoTTL(cfg *state.Config, gitRepo string) (time.Duration, error) {
+	if cfg == nil {
+		return 0, nil
+	}
+	ttl := cfg.ForRepo(gitRepo).TTL
+	if ttl == "" {
+		return 0, nil
+	}
+	d, err := parseAge(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ttl for %s: %w", gitRepo, err)
+	}
+	return d, nil
+}
+
+// expiryNote describes when a worktree expires under ttl, or returns "" if
+// it is pinned, does not expire, or is not close to expiring
+func expiryNote(entry state.WorktreeEntry, ttl time.Duration) string {
+	if ttl <= 0 || entry.Pinned {
+		return ""
+	}
+	left := ttl - time.Since(lastUsed(entry))
+	switch {
+	case left <= 0:
+		return "expired"
+	case left <= expiryWarning:
+		return "expires in " + formatAge(left)
+	}
+	return ""
+}
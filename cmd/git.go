@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitWorktree is a single entry from 'git worktree list --porcelain'
+type gitWorktree struct {
+	Path     string
+	Branch   string // Short branch name, or "detached HEAD"
+	Head     string // Commit the worktree is checked out at
+	Bare     bool
+	Locked   bool
+	Prunable bool
+}
+
+// gitOutput runs git in dir and returns its trimmed standard output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitCombinedOutput runs git in dir and returns its trimmed combined output,
+// which carries git's explanation when the command fails
+func gitCombinedOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// listGitWorktrees returns the worktrees git knows about for the repository containing dir
+func listGitWorktrees(dir string) ([]gitWorktree, error) {
+	output, err := gitOutput(dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktreeList(output), nil
+}
+
+// parseWorktreeList parses the output of 'git worktree list --porcelain'.
+// Records are separated by blank lines and start with a "worktree <path>" line.
+func parseWorktreeList(output string) []gitWorktree {
+	var worktrees []gitWorktree
+	var current *gitWorktree
+
+	flush := func() {
+		if current != nil {
+			if current.Branch == "" && current.Head != "" {
+				current.Branch = "detached HEAD"
+			}
+			worktrees = append(worktrees, *current)
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "worktree "):
+			flush()
+			current = &gitWorktree{Path: strings.TrimPrefix(line, "worktree ")}
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case line == "bare":
+			current.Bare = true
+		case line == "locked" || strings.HasPrefix(line, "locked "):
+			current.Locked = true
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			current.Prunable = true
+		}
+	}
+	flush()
+
+	return worktrees
+}
+
+// isWorktreeDirty reports whether the worktree at path has uncommitted or untracked changes
+func isWorktreeDirty(path string) (bool, error) {
+	output, err := gitOutput(path, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return output != "", nil
+}
+
+// mainCheckoutPath returns the main working tree of the repository containing dir.
+// For bare repositories the repository directory itself is returned.
+func mainCheckoutPath(dir string) (string, error) {
+	commonDir, err := gitOutput(dir, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Dir(commonDir), nil
+	}
+	return commonDir, nil
+}
+
+// aheadBehind counts the commits that are only on a and only on b
+func aheadBehind(dir, a, b string) (int, int, error) {
+	output, err := gitOutput(dir, "rev-list", "--left-right", "--count", a+"..."+b)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", output)
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// currentRepoName returns the organization/repository name of the repository in the current directory
+func currentRepoName() (string, error) {
+	remoteURL, err := gitOutput("", "config", "--get", "remote.origin.url")
+	if err != nil {
+		return "", fmt.Errorf("error getting remote origin URL: %w", err)
+	}
+	orgRepo := ParseRemoteURL(remoteURL)
+	if orgRepo == "" {
+		return "", fmt.Errorf("could not parse organization/username and repository name from remote URL: %s", remoteURL)
+	}
+	return orgRepo, nil
+}
+
+// defaultBranchRef returns the ref of the repository's default branch, preferring
+// the remote-tracking branch (e.g. "origin/main") over a local one
+func defaultBranchRef(dir string) (string, error) {
+	if ref, err := gitOutput(dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return ref, nil
+	}
+	for _, candidate := range []string{"origin/main", "origin/master", "main", "master"} {
+		if _, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the default branch")
+}
+
+// isAncestor reports whether commit a is reachable from commit b
+func isAncestor(dir, a, b string) bool {
+	_, err := gitOutput(dir, "merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// lockWorktree locks the linked worktree at path with git worktree lock so it
+// cannot be pruned or removed, and returns a function that unlocks it again.
+// Worktrees that are already locked (or cannot be) are left untouched.
+func lockWorktree(path, reason string) func() {
+	worktrees, err := listGitWorktrees(path)
+	if err != nil {
+		return func() {}
+	}
+	for _, wt := range worktrees {
+		if wt.Path == path && wt.Locked {
+			return func() {}
+		}
+	}
+	if _, err := gitCombinedOutput(path, "worktree", "lock", "--reason", reason, path); err != nil {
+		return func() {}
+	}
+	return func() {
+		gitCombinedOutput(path, "worktree", "unlock", path)
+	}
+}
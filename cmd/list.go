@@ -2,19 +2,64 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/garymjr/git-worktree-manager/pkg/state"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listSort      string
+	listColumns   string
+	listRepo      string
+	listStale     bool
+	listUnmanaged bool
+	listLabels    []string
+	listOlderThan string
+	listDirty     bool
+	listAll       bool
+)
+
+// listColumnNames are the columns accepted by --columns, in their default display order
+var listColumnNames = []string{"branch", "repo", "path", "status", "created", "accessed", "age", "labels", "aliases", "dirty"}
+
+// listRow is a single worktree as shown by the list command
+type listRow struct {
+	entry   state.WorktreeEntry // Only Path, BranchName and GitRepo are set for unmanaged worktrees
+	managed bool
+	stale   bool // Managed, but git does not know about the worktree
+	dirty   bool
+	active  bool
+	health  string // Only filled in by the tree view of list --all
+	expiry  string // Expiry warning, empty unless the worktree is about to expire
+}
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Short:   "List all git worktrees and their branches",
 	Aliases: []string{"ls"},
 	Run: func(cmd *cobra.Command, args []string) {
+		columns, err := parseListColumns(listColumns)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var olderThan time.Duration
+		if listOlderThan != "" {
+			olderThan, err = parseAge(listOlderThan)
+			if err != nil {
+				fmt.Printf("Error parsing --older-than: %v\n", err)
+				return
+			}
+		}
+
 		// Initialize state manager
 		stateManager, err := state.NewStateManager()
 		if err != nil {
@@ -22,6 +67,14 @@ var listCmd = &cobra.Command{
 			return
 		}
 
+		// Expiry warnings are best effort; a broken configuration is reported by prune
+		cfg, _ := state.LoadConfig()
+
+		if listAll {
+			listAllRepos(stateManager, cfg, listFilter(olderThan))
+			return
+		}
+
 		// Get the current working directory to identify the active worktree
 		currentDir, err := exec.Command("pwd").Output()
 		if err != nil {
@@ -32,85 +85,319 @@ var listCmd = &cobra.Command{
 
 		// Get managed worktrees from state
 		managedWorktrees := stateManager.ListWorktrees()
-		
-		// Execute 'git worktree list --porcelain' to get actual git worktrees
-		gitWorktreeListCmd := exec.Command("git", "worktree", "list", "--porcelain")
-		output, err := gitWorktreeListCmd.Output()
+
+		// Get actual git worktrees
+		worktrees, err := listGitWorktrees("")
 		if err != nil {
 			fmt.Printf("Error listing worktrees: %v\n", err)
 			return
 		}
-
-		lines := strings.Split(string(output), "\n")
 		gitWorktrees := make(map[string]string) // path -> branch
-
-		for i := 0; i < len(lines); i++ {
-			line := strings.TrimSpace(lines[i])
-			if line == "" {
-				continue
-			}
-
-			if strings.HasPrefix(line, "worktree ") {
-				worktreePath := strings.TrimPrefix(line, "worktree ")
-
-				// Read the next lines for branch and HEAD
-				var branch string
-				var isHead bool
-				for j := i + 1; j < len(lines); j++ {
-					subLine := strings.TrimSpace(lines[j])
-					if strings.HasPrefix(subLine, "branch ") {
-						branch = strings.TrimPrefix(subLine, "branch ")
-						branch = strings.TrimPrefix(branch, "refs/heads/")
-					} else if strings.HasPrefix(subLine, "HEAD ") {
-						isHead = true
-					} else if subLine == "" {
-						i = j // Move main loop index past this worktree's details
-						break
-					}
-					if j == len(lines)-1 { // End of output
-						i = j
-					}
-				}
-
-				if branch != "" {
-					gitWorktrees[worktreePath] = branch
-				} else if isHead {
-					gitWorktrees[worktreePath] = "detached HEAD"
-				}
+		for _, wt := range worktrees {
+			if wt.Branch != "" {
+				gitWorktrees[wt.Path] = wt.Branch
 			}
 		}
 
-		// Sort managed worktrees by branch name for consistent output
-		sort.Slice(managedWorktrees, func(i, j int) bool {
-			return managedWorktrees[i].BranchName < managedWorktrees[j].BranchName
-		})
+		// Unmanaged worktrees belong to the current repository
+		currentRepo := ""
+		if remoteURL, err := gitOutput("", "config", "--get", "remote.origin.url"); err == nil {
+			currentRepo = ParseRemoteURL(remoteURL)
+		}
 
-		fmt.Println("Managed Worktrees:")
+		var managedRows, unmanagedRows []*listRow
 		for _, entry := range managedWorktrees {
-			indicator := "  "
-			if strings.HasPrefix(currentDirPath, entry.Path) {
-				indicator = "* " // Indicate active worktree
-			}
+			_, exists := gitWorktrees[entry.Path]
+			managedRows = append(managedRows, &listRow{
+				entry:   entry,
+				managed: true,
+				stale:   !exists,
+				active:  isWithinDir(currentDirPath, entry.Path),
+			})
+			delete(gitWorktrees, entry.Path) // Remove from map to find unmanaged
+		}
+		for path, branch := range gitWorktrees {
+			unmanagedRows = append(unmanagedRows, &listRow{
+				entry:  state.WorktreeEntry{Path: path, BranchName: branch, GitRepo: currentRepo},
+				active: isWithinDir(currentDirPath, path),
+			})
+		}
+
+		markExpiringRows(managedRows, cfg)
 
-			status := "✓" // Exists in git
-			if _, exists := gitWorktrees[entry.Path]; !exists {
-				status = "✗" // Not found in git (stale)
+		keep := listFilter(olderThan)
+		managedRows = filterListRows(managedRows, keep)
+		unmanagedRows = filterListRows(unmanagedRows, keep)
+		if listUnmanaged {
+			managedRows = nil
+		}
+
+		// Checking for changes runs git in every worktree, so only do it when asked for
+		if listDirty || containsString(columns, "dirty") {
+			markDirtyRows(append(append([]*listRow{}, managedRows...), unmanagedRows...))
+			if listDirty {
+				isDirty := func(row *listRow) bool { return row.dirty }
+				managedRows = filterListRows(managedRows, isDirty)
+				unmanagedRows = filterListRows(unmanagedRows, isDirty)
 			}
+		}
 
-			fmt.Printf("%s%s (%s) [%s] %s\n", indicator, entry.Path, entry.BranchName, entry.GitRepo, status)
-			delete(gitWorktrees, entry.Path) // Remove from map to find unmanaged
+		if err := sortListRows(managedRows, listSort); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := sortListRows(unmanagedRows, listSort); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		width := terminalWidth()
+
+		if !listUnmanaged {
+			fmt.Println("Managed Worktrees:")
+			printListRows(managedRows, columns, width)
 		}
 
 		// Show any git worktrees not managed by our tool
-		if len(gitWorktrees) > 0 {
+		if listUnmanaged {
+			fmt.Println("Unmanaged Git Worktrees:")
+			printListRows(unmanagedRows, columns, width)
+		} else if len(unmanagedRows) > 0 {
 			fmt.Println("\nUnmanaged Git Worktrees:")
-			for path, branch := range gitWorktrees {
-				indicator := "  "
-				if strings.HasPrefix(currentDirPath, path) {
-					indicator = "* "
-				}
-				fmt.Printf("%s%s (%s) [unmanaged]\n", indicator, path, branch)
-			}
+			printListRows(unmanagedRows, columns, width)
 		}
 	},
 }
+
+func init() {
+	listCmd.Flags().StringVar(&listSort, "sort", "branch", "Sort by branch, repo, created, last-accessed or path")
+	listCmd.Flags().StringVar(&listColumns, "columns", "branch,repo,path,status", "Comma-separated columns to show ("+strings.Join(listColumnNames, ", ")+")")
+	listCmd.Flags().StringVar(&listRepo, "repo", "", "Only show worktrees of this repository (owner/repo)")
+	listCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	listCmd.Flags().BoolVar(&listStale, "stale", false, "Only show managed worktrees that git no longer knows about")
+	listCmd.Flags().BoolVar(&listUnmanaged, "unmanaged", false, "Only show worktrees that are not managed by this tool")
+	listCmd.Flags().StringSliceVar(&listLabels, "label", nil, "Only show worktrees carrying this label (repeatable)")
+	listCmd.RegisterFlagCompletionFunc("label", completeLabels)
+	listCmd.Flags().StringVar(&listOlderThan, "older-than", "", "Only show worktrees created longer ago than this (e.g. 30d, 12h)")
+	listCmd.Flags().BoolVar(&listDirty, "dirty", false, "Only show worktrees with uncommitted changes")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "Show worktrees of all repositories as a tree (works outside a git repository)")
+}
+
+// listFilter returns a predicate implementing the --repo, --stale, --label and --older-than filters
+func listFilter(olderThan time.Duration) func(*listRow) bool {
+	return func(row *listRow) bool {
+		if listRepo != "" && row.entry.GitRepo != listRepo {
+			return false
+		}
+		if listStale && !row.stale {
+			return false
+		}
+		for _, label := range listLabels {
+			if !row.entry.HasLabel(label) {
+				return false
+			}
+		}
+		if olderThan > 0 && (!row.managed || time.Since(row.entry.CreatedAt) < olderThan) {
+			return false
+		}
+		return true
+	}
+}
+
+// parseListColumns validates a comma-separated column list
+func parseListColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.TrimSpace(strings.ToLower(column))
+		if column == "" {
+			continue
+		}
+		if !containsString(listColumnNames, column) {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", column, strings.Join(listColumnNames, ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return columns, nil
+}
+
+// sortListRows orders rows by the given key, breaking ties by path so output is stable
+func sortListRows(rows []*listRow, key string) error {
+	var less func(a, b *listRow) bool
+	switch key {
+	case "branch", "":
+		less = func(a, b *listRow) bool { return a.entry.BranchName < b.entry.BranchName }
+	case "repo":
+		less = func(a, b *listRow) bool { return a.entry.GitRepo < b.entry.GitRepo }
+	case "created":
+		less = func(a, b *listRow) bool { return a.entry.CreatedAt.Before(b.entry.CreatedAt) }
+	case "last-accessed", "accessed":
+		// Most recently used first
+		less = func(a, b *listRow) bool { return a.entry.LastAccessed.After(b.entry.LastAccessed) }
+	case "path":
+		less = func(a, b *listRow) bool { return a.entry.Path < b.entry.Path }
+	default:
+		return fmt.Errorf("unknown sort key %q (available: branch, repo, created, last-accessed, path)", key)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if less(rows[i], rows[j]) {
+			return true
+		}
+		if less(rows[j], rows[i]) {
+			return false
+		}
+		return rows[i].entry.Path < rows[j].entry.Path
+	})
+	return nil
+}
+
+// filterListRows returns the rows for which keep returns true
+func filterListRows(rows []*listRow, keep func(*listRow) bool) []*listRow {
+	filtered := rows[:0]
+	for _, row := range rows {
+		if keep(row) {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+// markDirtyRows checks all rows for uncommitted changes concurrently
+func markDirtyRows(rows []*listRow) {
+	var wg sync.WaitGroup
+	for _, row := range rows {
+		if row.stale {
+			continue
+		}
+		wg.Add(1)
+		go func(row *listRow) {
+			defer wg.Done()
+			row.dirty, _ = isWorktreeDirty(row.entry.Path)
+		}(row)
+	}
+	wg.Wait()
+}
+
+// printListRows renders rows as a table with the requested columns
+func printListRows(rows []*listRow, columns []string, width int) {
+	if len(rows) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = strings.ToUpper(column)
+	}
+	t := newTable(headers...)
+	for i, column := range columns {
+		if column == "path" {
+			t.flex = i
+		}
+	}
+
+	markers := make([]string, len(rows))
+	for i, row := range rows {
+		markers[i] = "  "
+		if row.active {
+			markers[i] = "* " // Indicate active worktree
+		}
+		cells := make([]string, len(columns))
+		for j, column := range columns {
+			cells[j] = listCell(row, column)
+		}
+		t.addRow(cells...)
+	}
+
+	t.render(os.Stdout, markers, width)
+}
+
+// listCell returns the value of a single column for a row
+func listCell(row *listRow, column string) string {
+	entry := row.entry
+	switch column {
+	case "branch":
+		return entry.BranchName
+	case "repo":
+		return entry.GitRepo
+	case "path":
+		return entry.Path
+	case "status":
+		status := "✓" // Exists in git
+		switch {
+		case !row.managed:
+			return "unmanaged"
+		case row.stale:
+			status = "✗" // Not found in git (stale)
+		}
+		if entry.Pinned {
+			status += " pinned"
+		}
+		if row.expiry != "" {
+			status += " ⚠ " + row.expiry
+		}
+		return status
+	case "created":
+		if entry.CreatedAt.IsZero() {
+			return "-"
+		}
+		return entry.CreatedAt.Format("2006-01-02 15:04")
+	case "accessed":
+		if entry.LastAccessed.IsZero() {
+			return "-"
+		}
+		return entry.LastAccessed.Format("2006-01-02 15:04")
+	case "age":
+		if entry.CreatedAt.IsZero() {
+			return "-"
+		}
+		return formatAge(time.Since(entry.CreatedAt))
+	case "labels":
+		return strings.Join(entry.Labels, ",")
+	case "aliases":
+		return strings.Join(entry.Aliases, ",")
+	case "dirty":
+		if row.dirty {
+			return "yes"
+		}
+		return "no"
+	}
+	return ""
+}
+
+// markExpiringRows sets the expiry warning of rows whose repository has a ttl configured
+func markExpiringRows(rows []*listRow, cfg *state.Config) {
+	ttls := make(map[string]time.Duration)
+	for _, row := range rows {
+		if !row.managed {
+			continue
+		}
+		ttl, seen := ttls[row.entry.GitRepo]
+		if !seen {
+			ttl, _ = repoTTL(cfg, row.entry.GitRepo)
+			ttls[row.entry.GitRepo] = ttl
+		}
+		row.expiry = expiryNote(row.entry, ttl)
+	}
+}
+
+// isWithinDir reports whether path is dir or lies below it
+func isWithinDir(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
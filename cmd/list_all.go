@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// repoGroup collects the worktrees of a single repository for the tree view
+type repoGroup struct {
+	repo     string // Organization/repository name, as stored in state
+	host     string
+	owner    string
+	name     string
+	mainPath string // Main checkout, empty if unknown
+	rows     []*listRow
+}
+
+// listAllRepos prints every managed worktree grouped by host, owner and repository.
+// It does not depend on the current directory being inside a git repository.
+func listAllRepos(stateManager *state.StateManager, cfg *state.Config, keep func(*listRow) bool) {
+	groups := groupByRepo(stateManager.ListWorktrees())
+	if len(groups) == 0 {
+		fmt.Println("No managed worktrees")
+		return
+	}
+
+	currentDir, _ := os.Getwd()
+
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group *repoGroup) {
+			defer wg.Done()
+			group.checkHealth()
+		}(group)
+	}
+	wg.Wait()
+
+	// Filter first so the tree only contains repositories with matching worktrees
+	var visible []*repoGroup
+	for _, group := range groups {
+		rows := filterListRows(group.rows, keep)
+		if listDirty {
+			rows = filterListRows(rows, func(row *listRow) bool { return row.dirty })
+		}
+		if listUnmanaged {
+			rows = filterListRows(rows, func(row *listRow) bool { return !row.managed })
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := sortListRows(rows, listSort); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		markExpiringRows(rows, cfg)
+		for _, row := range rows {
+			row.active = currentDir != "" && isWithinDir(currentDir, row.entry.Path)
+			if row.entry.Pinned {
+				row.health += ", pinned"
+			}
+			if row.expiry != "" {
+				row.health += ", ⚠ " + row.expiry
+			}
+		}
+		group.rows = rows
+		visible = append(visible, group)
+	}
+	if len(visible) == 0 {
+		fmt.Println("No worktrees match")
+		return
+	}
+
+	width := terminalWidth()
+	for i, group := range visible {
+		if i == 0 || group.host != visible[i-1].host {
+			fmt.Println(group.host)
+		}
+
+		// A node is drawn as the last child when no later group shares its parent
+		lastOwner, lastRepo := true, true
+		for _, next := range visible[i+1:] {
+			if next.host == group.host {
+				if next.owner == group.owner {
+					lastRepo = false
+				} else {
+					lastOwner = false
+				}
+			}
+		}
+
+		ownerBranch, ownerIndent := treeBranch(lastOwner)
+		if i == 0 || group.host != visible[i-1].host || group.owner != visible[i-1].owner {
+			fmt.Println(ownerBranch + group.owner)
+		}
+
+		repoBranch, repoIndent := treeBranch(lastRepo)
+		mainPath := "unknown"
+		if group.mainPath != "" {
+			mainPath = group.mainPath
+			if _, err := os.Stat(group.mainPath); err != nil {
+				mainPath += ", missing"
+			}
+		}
+		fmt.Printf("%s%s%s (main: %s)\n", ownerIndent, repoBranch, group.name, mainPath)
+
+		printTreeRows(group.rows, ownerIndent+repoIndent, width)
+	}
+}
+
+// treeBranch returns the connector for a tree node and the indent for its children
+func treeBranch(last bool) (string, string) {
+	if last {
+		return "└── ", "    "
+	}
+	return "├── ", "│   "
+}
+
+// printTreeRows prints the worktrees of one repository as tree leaves
+func printTreeRows(rows []*listRow, indent string, width int) {
+	branchWidth, healthWidth := 0, 0
+	anyActive := false
+	for _, row := range rows {
+		anyActive = anyActive || row.active
+		if n := utf8.RuneCountInString(row.entry.BranchName); n > branchWidth {
+			branchWidth = n
+		}
+		if n := utf8.RuneCountInString(row.health); n > healthWidth {
+			healthWidth = n
+		}
+	}
+
+	for i, row := range rows {
+		branch, _ := treeBranch(i == len(rows)-1)
+		marker := ""
+		if row.active {
+			marker = "* "
+		} else if anyActive {
+			marker = "  "
+		}
+		prefix := indent + branch + marker + pad(row.entry.BranchName, branchWidth+2) + pad(row.health, healthWidth+2)
+		path := row.entry.Path
+		if width > 0 {
+			path = truncateLeft(path, max(width-utf8.RuneCountInString(prefix), 12))
+		}
+		fmt.Println(prefix + path)
+	}
+}
+
+// groupByRepo groups entries by repository, ordered by host, owner and name
+func groupByRepo(entries []state.WorktreeEntry) []*repoGroup {
+	byRepo := make(map[string]*repoGroup)
+	for _, entry := range entries {
+		group, exists := byRepo[entry.GitRepo]
+		if !exists {
+			group = &repoGroup{repo: entry.GitRepo, owner: "unknown", name: entry.GitRepo}
+			if i := strings.LastIndex(entry.GitRepo, "/"); i >= 0 {
+				group.owner, group.name = entry.GitRepo[:i], entry.GitRepo[i+1:]
+			}
+			byRepo[entry.GitRepo] = group
+		}
+		if group.host == "" {
+			group.host = ParseRemoteHost(entry.RemoteURL)
+		}
+		if group.mainPath == "" {
+			group.mainPath = entry.RepoPath
+		}
+		group.rows = append(group.rows, &listRow{entry: entry, managed: true})
+	}
+
+	groups := make([]*repoGroup, 0, len(byRepo))
+	for _, group := range byRepo {
+		if group.host == "" {
+			group.host = "local"
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.owner != b.owner {
+			return a.owner < b.owner
+		}
+		return a.name < b.name
+	})
+	return groups
+}
+
+// resolveMainPath finds the main checkout of the repository. Older entries do
+// not record it, in which case any surviving worktree is asked instead.
+func (g *repoGroup) resolveMainPath() string {
+	if g.mainPath == "" {
+		for _, row := range g.rows {
+			if path, err := mainCheckoutPath(row.entry.Path); err == nil {
+				g.mainPath = path
+				break
+			}
+		}
+	}
+	return g.mainPath
+}
+
+// checkoutDir returns a directory on disk from which git commands for the
+// repository can be run: the main checkout if it exists, otherwise any worktree.
+// All of them share the repository's refs and object store.
+func (g *repoGroup) checkoutDir() (string, error) {
+	if mainPath := g.resolveMainPath(); mainPath != "" {
+		if _, err := os.Stat(mainPath); err == nil {
+			return mainPath, nil
+		}
+	}
+	for _, row := range g.rows {
+		if _, err := os.Stat(row.entry.Path); err == nil {
+			return row.entry.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no checkout of %s found on disk", g.repo)
+}
+
+// checkHealth fills in the health of every worktree in the group, adding rows
+// for worktrees git knows about that are not managed by this tool.
+func (g *repoGroup) checkHealth() {
+	g.resolveMainPath()
+
+	var gitWorktrees map[string]gitWorktree
+	if g.mainPath != "" {
+		if worktrees, err := listGitWorktrees(g.mainPath); err == nil {
+			gitWorktrees = make(map[string]gitWorktree)
+			for _, wt := range worktrees {
+				gitWorktrees[wt.Path] = wt
+			}
+		}
+	}
+
+	managedPaths := make(map[string]bool)
+	for _, row := range g.rows {
+		managedPaths[row.entry.Path] = true
+	}
+	if gitWorktrees != nil {
+		for path, wt := range gitWorktrees {
+			if managedPaths[path] || path == g.mainPath || wt.Bare {
+				continue
+			}
+			g.rows = append(g.rows, &listRow{
+				entry: state.WorktreeEntry{Path: path, BranchName: wt.Branch, GitRepo: g.repo},
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, row := range g.rows {
+		wg.Add(1)
+		go func(row *listRow) {
+			defer wg.Done()
+			row.health = worktreeHealth(row, gitWorktrees)
+		}(row)
+	}
+	wg.Wait()
+}
+
+// worktreeHealth classifies a worktree. gitWorktrees is nil when the repository's git state is unknown.
+func worktreeHealth(row *listRow, gitWorktrees map[string]gitWorktree) string {
+	if _, err := os.Stat(row.entry.Path); os.IsNotExist(err) {
+		row.stale = true
+		return "missing"
+	}
+
+	var problems []string
+	if gitWorktrees != nil {
+		wt, exists := gitWorktrees[row.entry.Path]
+		if !exists {
+			row.stale = true
+			return "not registered in git"
+		}
+		if wt.Prunable {
+			problems = append(problems, "prunable")
+		}
+		if wt.Locked {
+			problems = append(problems, "locked")
+		}
+		if row.managed && wt.Branch != row.entry.BranchName {
+			problems = append(problems, "on "+wt.Branch)
+		}
+	}
+	if !row.managed {
+		problems = append(problems, "unmanaged")
+	}
+
+	dirty, err := isWorktreeDirty(row.entry.Path)
+	switch {
+	case err != nil:
+		problems = append(problems, "not a git worktree")
+	case dirty:
+		row.dirty = true
+		problems = append(problems, "dirty")
+	}
+
+	if len(problems) == 0 {
+		if gitWorktrees == nil {
+			return "ok (repository unknown)"
+		}
+		return "ok"
+	}
+	return strings.Join(problems, ", ")
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"golang.org/x/term"
+)
+
+// errPickerCancelled is returned when the picker is closed without a choice
+var errPickerCancelled = errors.New("cancelled")
+
+// pickerItem is a worktree offered by the picker
+type pickerItem struct {
+	entry   state.WorktreeEntry
+	line    string   // Aligned columns shown in the list
+	text    string   // Lower-case text the query is matched against
+	preview []string // Recent commits, loaded when the item is first highlighted
+}
+
+// picker is a fuzzy finder over worktrees, drawn on the terminal in raw mode
+type picker struct {
+	prompt   string
+	multi    bool
+	header   string
+	items    []*pickerItem
+	query    []rune
+	matches  []*pickerItem // Items matching the query, best first
+	cursor   int           // Index into matches
+	offset   int           // First match shown
+	selected map[*pickerItem]bool
+}
+
+// pickWorktree lets the user choose one worktree of the current repository
+// (of all repositories outside one)
+func pickWorktree(prompt string) (state.WorktreeEntry, error) {
+	entries, err := pickWorktrees(prompt, false)
+	if err != nil {
+		return state.WorktreeEntry{}, err
+	}
+	return entries[0], nil
+}
+
+// pickWorktrees lets the user choose worktrees of the current repository (of
+// all repositories outside one). With multi, several can be marked with Tab.
+func pickWorktrees(prompt string, multi bool) ([]state.WorktreeEntry, error) {
+	in, out := int(os.Stdin.Fd()), os.Stderr
+	if !term.IsTerminal(in) || !term.IsTerminal(int(out.Fd())) {
+		return nil, fmt.Errorf("no branch name given and not running in a terminal")
+	}
+
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil, fmt.Errorf("initializing state manager: %w", err)
+	}
+	var entries []state.WorktreeEntry
+	if repo, err := currentRepoName(); err == nil {
+		entries = stateManager.ListWorktreesByRepo(repo)
+	} else {
+		entries = stateManager.ListWorktrees()
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no managed worktrees to choose from")
+	}
+	sort.Slice(entries, func(i, j int) bool { return lastUsed(entries[i]).After(lastUsed(entries[j])) })
+
+	p := newPicker(entries, prompt, multi)
+	oldState, err := term.MakeRaw(in)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprint(out, "\x1b[?1049h") // Switch to the alternate screen
+	defer func() {
+		fmt.Fprint(out, "\x1b[?1049l")
+		term.Restore(in, oldState)
+	}()
+
+	buf := make([]byte, 256)
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		p.draw(out, width, height)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		done, err := p.handle(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return p.result(), nil
+		}
+	}
+}
+
+func newPicker(entries []state.WorktreeEntry, prompt string, multi bool) *picker {
+	rows := make([]*listRow, len(entries))
+	for i, entry := range entries {
+		_, err := os.Stat(entry.Path)
+		rows[i] = &listRow{entry: entry, managed: true, stale: err != nil}
+	}
+	markDirtyRows(rows)
+
+	t := newTable("REPO", "BRANCH", "AGE", "STATUS")
+	for _, row := range rows {
+		status := "clean"
+		switch {
+		case row.stale:
+			status = "missing"
+		case row.dirty:
+			status = "dirty"
+		}
+		t.addRow(row.entry.GitRepo, row.entry.BranchName, formatAge(time.Since(row.entry.CreatedAt)), status)
+	}
+	var b strings.Builder
+	t.render(&b, nil, 0)
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+
+	p := &picker{prompt: prompt, multi: multi, header: lines[0], selected: make(map[*pickerItem]bool)}
+	for i, entry := range entries {
+		p.items = append(p.items, &pickerItem{
+			entry: entry,
+			line:  lines[i+1],
+			text:  strings.ToLower(entry.GitRepo + "/" + entry.BranchName),
+		})
+	}
+	p.filter()
+	return p
+}
+
+// filter matches the items against the query, keeping the best matches first
+func (p *picker) filter() {
+	query := []rune(strings.ToLower(string(p.query)))
+	scores := make(map[*pickerItem]int)
+	p.matches = p.matches[:0]
+	for _, item := range p.items {
+		if score, ok := fuzzyScore(item.text, query); ok {
+			scores[item] = score
+			p.matches = append(p.matches, item)
+		}
+	}
+	sort.SliceStable(p.matches, func(i, j int) bool { return scores[p.matches[i]] < scores[p.matches[j]] })
+	p.cursor, p.offset = 0, 0
+}
+
+// fuzzyScore reports whether the characters of query appear in text in order.
+// Lower scores are better: they count the characters skipped before and
+// between the matches.
+func fuzzyScore(text string, query []rune) (int, bool) {
+	if len(query) == 0 {
+		return 0, true
+	}
+	score, matched, last := 0, 0, -1
+	for i, r := range []rune(text) {
+		if r != query[matched] {
+			continue
+		}
+		score += i - last - 1
+		last = i
+		matched++
+		if matched == len(query) {
+			return score, true
+		}
+	}
+	return 0, false
+}
+
+// handle processes the keys read from the terminal and reports whether the
+// choice was made
+func (p *picker) handle(keys []byte) (bool, error) {
+	for len(keys) > 0 {
+		switch key := keys[0]; {
+		case key == 3 || (key == 27 && len(keys) == 1): // Ctrl-C, Esc
+			return false, errPickerCancelled
+		case key == 27:
+			// Escape sequence; only the arrow keys are used
+			if len(keys) >= 3 && (keys[1] == '[' || keys[1] == 'O') {
+				switch keys[2] {
+				case 'A':
+					p.move(-1)
+				case 'B':
+					p.move(1)
+				}
+			}
+			return false, nil
+		case key == '\r' || key == '\n':
+			return len(p.matches) > 0 || len(p.selected) > 0, nil
+		case key == '\t':
+			if p.multi && len(p.matches) > 0 {
+				item := p.matches[p.cursor]
+				if p.selected[item] {
+					delete(p.selected, item)
+				} else {
+					p.selected[item] = true
+				}
+				p.move(1)
+			}
+		case key == 16 || key == 11: // Ctrl-P, Ctrl-K
+			p.move(-1)
+		case key == 14: // Ctrl-N
+			p.move(1)
+		case key == 127 || key == 8: // Backspace
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case key == 21: // Ctrl-U
+			p.query = nil
+			p.filter()
+		case key >= 32:
+			r, size := utf8.DecodeRune(keys)
+			p.query = append(p.query, r)
+			p.filter()
+			keys = keys[size:]
+			continue
+		}
+		keys = keys[1:]
+	}
+	return false, nil
+}
+
+// move moves the cursor by delta, stopping at either end of the list
+func (p *picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// result returns the marked worktrees, or the highlighted one if none are marked
+func (p *picker) result() []state.WorktreeEntry {
+	var entries []state.WorktreeEntry
+	for _, item := range p.items {
+		if p.selected[item] {
+			entries = append(entries, item.entry)
+		}
+	}
+	if len(entries) == 0 && len(p.matches) > 0 {
+		entries = append(entries, p.matches[p.cursor].entry)
+	}
+	return entries
+}
+
+// draw renders the picker: the query, the list of matches and a preview of
+// the recent commits of the highlighted worktree
+func (p *picker) draw(w io.Writer, width, height int) {
+	listHeight := (height - 3) / 2 // Prompt, header and separator take a line each
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+listHeight {
+		p.offset = p.cursor - listHeight + 1
+	}
+
+	prompt := p.prompt + "> "
+	lines := []string{prompt + string(p.query), "\x1b[2m" + truncate("  "+p.header, width) + "\x1b[0m"}
+	for i := p.offset; i < p.offset+listHeight; i++ {
+		if i >= len(p.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		item := p.matches[i]
+		marker := "  "
+		if p.selected[item] {
+			marker = "* "
+		}
+		line := truncate(marker+item.line, width)
+		if i == p.cursor {
+			line = "\x1b[7m" + pad(line, width) + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	hint := "Enter: choose  Esc: cancel"
+	if p.multi {
+		hint = "Tab: mark  " + hint
+	}
+	separator := []rune(fmt.Sprintf("── %d/%d ── %s ", len(p.matches), len(p.items), hint) + strings.Repeat("─", width))
+	lines = append(lines, string(separator[:width]))
+	if len(p.matches) > 0 {
+		for _, commit := range p.matches[p.cursor].commits() {
+			if len(lines) >= height {
+				break
+			}
+			lines = append(lines, truncate(commit, width))
+		}
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	// Clear the screen, draw every line and put the cursor after the query
+	frame := "\x1b[H\x1b[2J" + strings.Join(lines, "\r\n")
+	frame += fmt.Sprintf("\x1b[1;%dH", utf8.RuneCountInString(prompt)+len(p.query)+1)
+	io.WriteString(w, frame)
+}
+
+// commits returns the recent commits shown in the preview
+func (item *pickerItem) commits() []string {
+	if item.preview == nil {
+		out, err := gitOutput(item.entry.Path, "log", "--no-decorate", "--format=%h %s (%cr)", "-n", "50")
+		if err != nil {
+			item.preview = []string{"(no commits to show: " + strings.TrimSpace(err.Error()) + ")"}
+		} else {
+			item.preview = splitLines(out)
+		}
+	}
+	return item.preview
+}
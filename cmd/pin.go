@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:               "pin [branch-name]",
+	Short:             "Pin a worktree so it never expires",
+	Long:              `Pinned worktrees are exempt from expiry and are never removed by prune.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], true)
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:               "unpin [branch-name]",
+	Short:             "Unpin a worktree so it can expire again",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
+
+// setPinned pins or unpins the worktree of branchName in the current repository
+func setPinned(branchName string, pinned bool) {
+	orgRepo, err := currentRepoName()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		fmt.Printf("Error initializing state manager: %v\n", err)
+		return
+	}
+
+	entry, err := resolveWorktree(stateManager, orgRepo, branchName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	branchName = entry.BranchName
+
+	if err := stateManager.SetPinned(orgRepo, branchName, pinned); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if pinned {
+		printDone("Pinned worktree for branch '%s'\n", branchName)
+	} else {
+		printDone("Unpinned worktree for branch '%s'\n", branchName)
+	}
+}
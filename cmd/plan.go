@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dryRun       bool
+	outputFormat string
+)
+
+// planStep is a single change a command would make in dry-run mode
+type planStep struct {
+	Kind   string `json:"kind"`             // git, state, file, process or run
+	Action string `json:"action"`           // What happens to the target, e.g. "run", "add" or "delete"
+	Target string `json:"target"`           // Command line, state ID or path
+	Dir    string `json:"dir,omitempty"`    // Directory commands run in
+	Detail string `json:"detail,omitempty"` // Additional information
+}
+
+// executionPlan collects the changes of a command run with --dry-run
+type executionPlan struct {
+	Command string     `json:"command"`
+	Steps   []planStep `json:"steps"`
+
+	mu     sync.Mutex
+	stdout *os.File // Standard output while the command's own output goes to stderr
+}
+
+// plan is the plan being recorded, nil unless --dry-run is in effect
+var plan *executionPlan
+
+// startDryRun begins recording the changes of cmd instead of making them. For
+// JSON output, the command's messages are moved to stderr so that stdout only
+// carries the plan.
+func startDryRun(cmd *cobra.Command) error {
+	if outputFormat != "text" && outputFormat != "json" {
+		// Execute reports the error; the usage text would only bury it
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		return fmt.Errorf("unknown output format %q (available: text, json)", outputFormat)
+	}
+	plan = &executionPlan{Command: cmd.CommandPath(), Steps: []planStep{}}
+	state.SetDryRun(func(action, target, detail string) {
+		plan.add(planStep{Kind: "state", Action: action, Target: target, Detail: detail})
+	})
+	if outputFormat == "json" {
+		plan.stdout = os.Stdout
+		os.Stdout = os.Stderr
+	} else {
+		fmt.Println("Dry run: nothing will be changed")
+		fmt.Println()
+	}
+	return nil
+}
+
+// finishDryRun prints the recorded plan
+func finishDryRun() {
+	if outputFormat == "json" {
+		os.Stdout = plan.stdout
+		data, _ := json.MarshalIndent(plan, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println()
+	if len(plan.Steps) == 0 {
+		fmt.Println("Plan: no changes")
+		return
+	}
+	fmt.Println("Plan:")
+	t := newTable("KIND", "ACTION", "TARGET", "DETAIL")
+	for _, step := range plan.Steps {
+		detail := step.Detail
+		if step.Dir != "" {
+			detail = strings.TrimSpace("in " + step.Dir + " " + detail)
+		}
+		t.addRow(step.Kind, step.Action, step.Target, detail)
+	}
+	markers := make([]string, len(plan.Steps))
+	for i := range markers {
+		markers[i] = "  "
+	}
+	t.render(os.Stdout, markers, terminalWidth())
+}
+
+// add records a step; commands may record from several goroutines
+func (p *executionPlan) add(step planStep) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Steps = append(p.Steps, step)
+}
+
+// gitChange runs a git command that modifies a repository, or only records it in dry-run mode
+func gitChange(dir string, args ...string) (string, error) {
+	if plan != nil {
+		plan.add(planStep{Kind: "git", Action: "run", Target: "git " + strings.Join(args, " "), Dir: dir})
+		return "", nil
+	}
+	return gitCombinedOutput(dir, args...)
+}
+
+// fileChange applies a change to the filesystem, or only records it in dry-run mode
+func fileChange(action, path string, apply func() error) error {
+	if plan != nil {
+		plan.add(planStep{Kind: "file", Action: action, Target: path})
+		return nil
+	}
+	return apply()
+}
+
+// planFile records a filesystem change that a git command makes as a side
+// effect, so the plan shows it; outside dry-run mode it does nothing
+func planFile(action, path string) {
+	if plan != nil {
+		plan.add(planStep{Kind: "file", Action: action, Target: path})
+	}
+}
+
+// planRun records a command that would be started, reporting whether dry-run
+// mode is on and the command must therefore not run
+func planRun(command, dir string) bool {
+	if plan == nil {
+		return false
+	}
+	plan.add(planStep{Kind: "run", Action: "run", Target: command, Dir: dir})
+	return true
+}
+
+// printDone reports a completed change; in dry-run mode nothing was changed,
+// so the plan speaks for itself and nothing is printed
+func printDone(format string, args ...any) {
+	if plan == nil {
+		fmt.Printf(format, args...)
+	}
+}
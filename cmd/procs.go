@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// worktreeProcess is a running process that uses a worktree
+type worktreeProcess struct {
+	pid     int
+	command string
+	reason  string // Working directory or the open file inside the worktree
+}
+
+// processesUsing lists the processes whose working directory or open files
+// are inside dir. Symlinks in dir are resolved because the kernel reports real paths.
+func processesUsing(dir string) ([]worktreeProcess, error) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return findProcessesInDir(dir)
+}
+
+// printProcesses lists procs as an indented table
+func printProcesses(procs []worktreeProcess) {
+	t := newTable("PID", "COMMAND", "USING")
+	for _, proc := range procs {
+		t.addRow(fmt.Sprint(proc.pid), proc.command, proc.reason)
+	}
+	markers := make([]string, len(procs))
+	for i := range markers {
+		markers[i] = "  "
+	}
+	t.render(os.Stdout, markers, terminalWidth())
+}
+
+// terminateProcesses asks procs to exit and waits for them to stop using dir.
+// The shell that started this command is left alone. Returns the processes
+// that are still using dir.
+func terminateProcesses(procs []worktreeProcess, dir string) ([]worktreeProcess, error) {
+	if plan != nil {
+		for _, proc := range procs {
+			plan.add(planStep{Kind: "process", Action: "terminate", Target: fmt.Sprint(proc.pid), Detail: proc.command})
+		}
+		return nil, nil
+	}
+
+	for _, proc := range procs {
+		if proc.pid == os.Getppid() {
+			fmt.Printf("Not terminating %s (%d): it started this command\n", proc.command, proc.pid)
+			continue
+		}
+		p, err := os.FindProcess(proc.pid)
+		if err != nil {
+			continue
+		}
+		if err := p.Signal(syscall.SIGTERM); err == nil {
+			fmt.Printf("Terminated %s (%d)\n", proc.command, proc.pid)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		remaining, err := processesUsing(dir)
+		if err != nil || len(remaining) == 0 || time.Now().After(deadline) {
+			return remaining, err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// findProcessesInDir scans /proc for processes whose working directory or
+// open files are inside dir. Processes that cannot be inspected (typically
+// those of other users) are skipped.
+func findProcessesInDir(dir string) ([]worktreeProcess, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []worktreeProcess
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		procDir := filepath.Join("/proc", entry.Name())
+
+		reason := ""
+		if cwd, err := os.Readlink(filepath.Join(procDir, "cwd")); err == nil && isWithinDir(cwd, dir) {
+			reason = "working directory"
+		} else if fds, err := os.ReadDir(filepath.Join(procDir, "fd")); err == nil {
+			for _, fd := range fds {
+				target, err := os.Readlink(filepath.Join(procDir, "fd", fd.Name()))
+				if err == nil && isWithinDir(target, dir) {
+					reason = "open file " + target
+					break
+				}
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		comm, _ := os.ReadFile(filepath.Join(procDir, "comm"))
+		procs = append(procs, worktreeProcess{pid: pid, command: strings.TrimSpace(string(comm)), reason: reason})
+	}
+	return procs, nil
+}
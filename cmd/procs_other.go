@@ -0,0 +1,9 @@
+//go:build !linux
+
+package cmd
+
+// findProcessesInDir is only implemented on Linux; elsewhere no process is
+// ever reported as using a worktree
+func findProcessesInDir(dir string) ([]worktreeProcess, error) {
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// defaultPromptFormat is the prompt segment used unless configured otherwise
+const defaultPromptFormat = "[{{.Repo}} ⎇ {{.Branch}} • wt]"
+
+var promptFormat string
+
+// promptData is what prompt templates are executed with
+type promptData struct {
+	ID      string   // owner/repo/branch
+	Repo    string   // owner/repo
+	Branch  string   // Branch name
+	Path    string   // Worktree root
+	Subdir  string   // Current directory relative to the worktree root, "." at the root
+	Labels  []string // Labels of the worktree
+	Aliases []string // Nicknames of the worktree
+	Pinned  bool     // Whether the worktree is pinned
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a prompt segment for the managed worktree in the current directory",
+	Long: `Print a short description of the managed worktree containing the current
+directory, for use in a shell prompt. Nothing is printed outside managed
+worktrees. Only the state file is read, git is not run, so it is fast enough
+to run for every prompt.
+
+The segment is a Go template. It is taken from --format, the prompt_format
+setting or the default ` + defaultPromptFormat + `. Available fields:
+.ID, .Repo, .Branch, .Path, .Subdir, .Labels, .Aliases and .Pinned; 'join'
+joins a list, e.g. {{join .Labels ","}}.
+
+  PS1='$(git-worktree-manager prompt) \w \$ '                    # bash
+  git-worktree-manager prompt --format '{{.Branch}}{{if .Pinned}} 📌{{end}}'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := os.Getwd()
+		if err != nil {
+			return
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			promptError(fmt.Errorf("initializing state manager: %w", err))
+		}
+		entry, found := stateManager.WorktreeAt(dir)
+		if !found {
+			// Worktrees may be registered under a path with symlinks resolved
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil && resolved != dir {
+				dir = resolved
+				entry, found = stateManager.WorktreeAt(dir)
+			}
+		}
+		if !found {
+			return
+		}
+
+		format := promptFormat
+		if !cmd.Flags().Changed("format") {
+			if cfg, err := state.LoadConfig(); err == nil {
+				format = cfg.ForRepo(entry.GitRepo).PromptFormat
+			}
+			if format == "" {
+				format = defaultPromptFormat
+			}
+		}
+		tmpl, err := parsePromptFormat(format)
+		if err != nil {
+			promptError(err)
+		}
+
+		subdir, err := filepath.Rel(entry.Path, dir)
+		if err != nil {
+			subdir = "."
+		}
+		data := promptData{
+			ID:      entry.ID,
+			Repo:    entry.GitRepo,
+			Branch:  entry.BranchName,
+			Path:    entry.Path,
+			Subdir:  subdir,
+			Labels:  entry.Labels,
+			Aliases: entry.Aliases,
+			Pinned:  entry.Pinned,
+		}
+		// No trailing newline, so the output can be embedded in a prompt as is.
+		// The segment is built first so a failing template prints nothing.
+		var segment strings.Builder
+		if err := tmpl.Execute(&segment, data); err != nil {
+			promptError(err)
+		}
+		fmt.Print(segment.String())
+	},
+}
+
+func init() {
+	promptCmd.Flags().StringVarP(&promptFormat, "format", "f", "", "Template of the prompt segment (default "+defaultPromptFormat+")")
+	rootCmd.AddCommand(promptCmd)
+}
+
+// promptError reports err on stderr and exits, so that nothing ends up inside
+// the prompt
+func promptError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
+// parsePromptFormat parses a prompt template
+func parsePromptFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Funcs(template.FuncMap{"join": strings.Join}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt format: %w", err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// defaultProtectedPatterns match branches that are never deleted or pruned, in
+// addition to the repository's default branch
+var defaultProtectedPatterns = []string{"release/*"}
+
+// protectedBranch explains why branch must not be deleted or pruned, or returns
+// "" if it may be. patterns are the configured protected patterns, if any;
+// gitRoot is any checkout of the repository.
+func protectedBranch(patterns []string, gitRoot, branch string) string {
+	if base, err := defaultBranchRef(gitRoot); err == nil && branch == base[strings.Index(base, "/")+1:] {
+		return "default branch"
+	}
+	if len(patterns) == 0 {
+		patterns = defaultProtectedPatterns
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return "matches " + pattern
+		}
+	}
+	return ""
+}
+
+// checkBranchDeletion returns an error if the branch of entry is protected
+func checkBranchDeletion(entry state.WorktreeEntry, gitRoot string) error {
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	if reason := protectedBranch(cfg.ForRepo(entry.GitRepo).Protected, gitRoot, entry.BranchName); reason != "" {
+		return fmt.Errorf("branch '%s' is protected (%s); change this with 'config set protected'", entry.BranchName, reason)
+	}
+	return nil
+}
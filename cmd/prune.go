@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneSelector     worktreeSelector
+	pruneDeleteBranch bool
+	pruneNoFetch      bool
+	pruneOlderThan    string
+	pruneYes          bool
+)
+
+// pruneCandidate is a managed worktree whose branch is finished or which expired
+type pruneCandidate struct {
+	entry   state.WorktreeEntry
+	gitRoot string // Checkout used to run git commands for the repository
+	reason  string // Why the worktree is no longer needed
+	merged  bool   // The branch was shown to be merged, so it may be force-deleted
+	skip    string // Why the worktree is kept anyway, empty if it will be removed
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove worktrees whose branch was merged or whose upstream is gone",
+	Long: `Find managed worktrees whose branch is finished and remove them.
+
+A branch is finished when it was merged into the repository's default branch,
+when its changes were squash-merged (detected by comparing trees), or when its
+upstream branch was deleted on the remote.
+
+Worktrees that have not been accessed within --older-than, or within the
+repository's configured ttl (see 'config set ttl'), expire and are pruned too.
+
+With --delete-branch, merged branches are deleted even if git does not see the
+merge; other branches only if git considers them merged.
+
+Worktrees with uncommitted changes, pinned worktrees and worktrees of protected
+branches (see 'config set protected') are never removed.
+The plan is printed and confirmed before anything is removed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := pruneSelector.validate(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := pruneSelector.resolveRepo(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var olderThan time.Duration
+		if pruneOlderThan != "" {
+			var err error
+			olderThan, err = parseAge(pruneOlderThan)
+			if err != nil {
+				fmt.Printf("Error parsing --older-than: %v\n", err)
+				return
+			}
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+
+		var candidates []pruneCandidate
+		for _, group := range groupByRepo(pruneSelector.selectFrom(stateManager)) {
+			ttl := olderThan
+			if ttl == 0 {
+				if ttl, err = repoTTL(cfg, group.repo); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					continue
+				}
+			}
+			found, err := findPruneCandidates(group, ttl, !pruneNoFetch, pruneDeleteBranch, cfg.ForRepo(group.repo))
+			if err != nil {
+				fmt.Printf("Error checking %s: %v\n", group.repo, err)
+				continue
+			}
+			candidates = append(candidates, found...)
+		}
+
+		if len(candidates) == 0 {
+			fmt.Println("Nothing to prune")
+			return
+		}
+
+		printPrunePlan(candidates, pruneDeleteBranch)
+
+		toRemove := 0
+		for _, candidate := range candidates {
+			if candidate.skip == "" {
+				toRemove++
+			}
+		}
+		if toRemove == 0 {
+			fmt.Println("Nothing to prune")
+			return
+		}
+		if !pruneYes && !confirm(fmt.Sprintf("Remove %d worktree(s)?", toRemove)) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		removed := 0
+		for _, candidate := range candidates {
+			if candidate.skip != "" {
+				continue
+			}
+			// Only merged branches are deleted with -D; git refuses to delete others with unmerged commits
+			opts := removeOptions{deleteBranch: pruneDeleteBranch, forceBranch: candidate.merged}
+			if err := removeWorktree(stateManager, candidate.entry, candidate.gitRoot, opts); err != nil {
+				fmt.Printf("Error pruning '%s': %v\n", candidate.entry.BranchName, err)
+				continue
+			}
+			removed++
+		}
+		fmt.Printf("\nPruned %d of %d worktree(s)\n", removed, toRemove)
+	},
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneSelector.repo, "repo", "", "Repository to prune (owner/repo); defaults to the current repository")
+	pruneCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	pruneCmd.Flags().BoolVarP(&pruneSelector.all, "all", "a", false, "Prune worktrees of all repositories")
+	pruneCmd.Flags().BoolVarP(&pruneDeleteBranch, "delete-branch", "b", false, "Also delete the local branch of pruned worktrees")
+	pruneCmd.Flags().BoolVar(&pruneNoFetch, "no-fetch", false, "Do not fetch before checking which upstream branches are gone")
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "Also prune worktrees not accessed for this long (e.g. 30d), overriding the configured ttl")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Do not ask for confirmation")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// printPrunePlan shows what prune is about to do
+func printPrunePlan(candidates []pruneCandidate, deleteBranch bool) {
+	fmt.Println("Prune plan:")
+	t := newTable("BRANCH", "REPO", "REASON", "ACTION")
+	for _, candidate := range candidates {
+		action := "remove worktree"
+		if deleteBranch {
+			action = "remove worktree and branch"
+		}
+		if candidate.skip != "" {
+			action = "skip (" + candidate.skip + ")"
+		}
+		t.addRow(candidate.entry.BranchName, candidate.entry.GitRepo, candidate.reason, action)
+	}
+	t.render(os.Stdout, nil, terminalWidth())
+	fmt.Println()
+}
+
+// findPruneCandidates returns the worktrees of a repository whose branch is
+// finished or which were not accessed within ttl (when ttl is positive).
+// deleteBranch tells whether the branches are deleted too.
+// rc supplies the precious file and protected branch patterns that keep a worktree from being pruned.
+func findPruneCandidates(group *repoGroup, ttl time.Duration, fetch, deleteBranch bool, rc state.RepoConfig) ([]pruneCandidate, error) {
+	gitRoot, err := group.checkoutDir()
+	if err != nil {
+		return nil, err
+	}
+	if fetch {
+		if out, err := gitChange(gitRoot, "fetch", "--all", "--prune"); err != nil {
+			return nil, fmt.Errorf("fetch failed: %v: %s", err, out)
+		}
+	}
+	base, err := defaultBranchRef(gitRoot)
+	if err != nil {
+		return nil, err
+	}
+	baseName := base[strings.Index(base, "/")+1:]
+
+	sortListRows(group.rows, "branch")
+	var candidates []pruneCandidate
+	for _, row := range group.rows {
+		entry := row.entry
+		if entry.BranchName == baseName {
+			continue
+		}
+		reason := branchFinished(gitRoot, entry, base)
+		if reason == "" && ttl > 0 {
+			if idle := time.Since(lastUsed(entry)); idle > ttl {
+				reason = "not accessed for " + formatAge(idle)
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		candidate := pruneCandidate{entry: entry, gitRoot: gitRoot, reason: reason, merged: isMergeReason(reason)}
+		if entry.Pinned {
+			candidate.skip = "pinned"
+		} else if protected := protectedBranch(rc.Protected, gitRoot, entry.BranchName); protected != "" {
+			candidate.skip = "protected branch, " + protected
+		} else if _, err := os.Stat(entry.Path); err == nil {
+			dirty, err := isWorktreeDirty(entry.Path)
+			switch {
+			case err != nil:
+				candidate.skip = "cannot read status"
+			case dirty:
+				candidate.skip = "uncommitted changes"
+			}
+			if candidate.skip == "" {
+				// The commits of a merged branch are safe, so only local files and
+				// operations matter for it. An expired or gone branch may hold work
+				// that exists nowhere else.
+				if report, err := checkWorktree(entry, gitRoot, rc.Precious); err != nil {
+					candidate.skip = "cannot check worktree"
+				} else if blockers := report.blockers(deleteBranch); !candidate.merged && len(blockers) > 0 {
+					candidate.skip = "would lose " + strings.Join(blockers, ", ")
+				} else if len(report.precious) > 0 {
+					candidate.skip = "precious ignored files"
+				} else if report.operation != "" {
+					candidate.skip = report.operation + " in progress"
+				} else if procs, _ := processesUsing(entry.Path); len(procs) > 0 {
+					candidate.skip = fmt.Sprintf("in use by %d process(es)", len(procs))
+				}
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// branchFinished explains why the branch of entry no longer needs a worktree,
+// or returns "" if it is still in progress. base is the default branch ref.
+func branchFinished(gitRoot string, entry state.WorktreeEntry, base string) string {
+	branchRef := "refs/heads/" + entry.BranchName
+	if _, err := gitOutput(gitRoot, "rev-parse", "--verify", "--quiet", branchRef); err != nil {
+		return "" // Branch does not exist locally; nothing to compare
+	}
+
+	if track, err := gitOutput(gitRoot, "for-each-ref", "--format=%(upstream:track)", branchRef); err == nil && track == "[gone]" {
+		return "upstream deleted"
+	}
+
+	if isAncestor(gitRoot, branchRef, base) {
+		// A branch without commits of its own is trivially contained in the
+		// default branch, so only count it as merged if it brought any
+		if !branchHasWork(gitRoot, entry, branchRef, base) {
+			return ""
+		}
+		return "merged into " + base
+	}
+
+	// Squash merges leave no ancestry behind. If merging the branch into the
+	// default branch would not change its tree, all of its changes are already there.
+	mergedTree, err := gitOutput(gitRoot, "merge-tree", "--write-tree", base, branchRef)
+	if err != nil {
+		return "" // Conflicts, or a git version without --write-tree
+	}
+	baseTree, err := gitOutput(gitRoot, "rev-parse", base+"^{tree}")
+	if err == nil && strings.SplitN(mergedTree, "\n", 2)[0] == baseTree {
+		return "squash-merged into " + base
+	}
+
+	return ""
+}
+
+// reflogEntry is an entry of a ref's reflog
+type reflogEntry struct {
+	commit  string
+	time    int64 // When the ref was updated, in Unix seconds
+	subject string
+}
+
+// reflogEntries returns the reflog of ref, newest first, or nil if it has none
+func reflogEntries(gitRoot, ref string) []reflogEntry {
+	output, err := gitOutput(gitRoot, "reflog", "show", "--date=unix", "--format=%H %gd %gs", ref)
+	if err != nil {
+		return nil
+	}
+	var entries []reflogEntry
+	for _, line := range splitLines(output) {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		selector := fields[1] // ref@{<time>}
+		i := strings.LastIndex(selector, "@{")
+		if i < 0 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.TrimSuffix(selector[i+2:], "}"), 10, 64)
+		if err != nil {
+			continue
+		}
+		entry := reflogEntry{commit: fields[0], time: seconds}
+		if len(fields) == 3 {
+			entry.subject = fields[2]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// isMergeReason reports whether a reason given by branchFinished proves that
+// the branch's changes are in the default branch
+func isMergeReason(reason string) bool {
+	return strings.HasPrefix(reason, "merged") || strings.HasPrefix(reason, "squash-merged")
+}
+
+// branchHasWork reports whether the branch holds commits that were not in the
+// default branch base when it was created: commits made on it, or the ones it
+// was created with, such as a colleague's branch checked out for review. The
+// reflogs answer this; without them, the tip is compared with the time the
+// worktree was registered.
+func branchHasWork(gitRoot string, entry state.WorktreeEntry, branchRef, base string) bool {
+	if reflog := reflogEntries(gitRoot, branchRef); len(reflog) > 0 {
+		created := reflog[len(reflog)-1]
+		if len(reflog) > 1 || !strings.HasPrefix(created.subject, "branch: Created from") {
+			return true // Moved since it was created
+		}
+		// Never moved: it brought work unless base already had its tip then
+		for _, b := range reflogEntries(gitRoot, base) {
+			if b.time <= created.time {
+				return !isAncestor(gitRoot, created.commit, b.commit)
+			}
+		}
+	}
+
+	tip, err := gitOutput(gitRoot, "log", "-1", "--format=%ct", branchRef)
+	if err != nil || entry.CreatedAt.IsZero() {
+		return true
+	}
+	seconds, err := strconv.ParseInt(tip, 10, 64)
+	if err != nil {
+		return true
+	}
+	return !time.Unix(seconds, 0).Before(entry.CreatedAt.Truncate(time.Second))
+}
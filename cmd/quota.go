@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// checkQuota checks whether another worktree for gitRepo fits within the
+// configured quotas and prints a message for every quota that is exceeded.
+// It returns false only when a quota is exceeded and quota_mode is "block".
+func checkQuota(stateManager *state.StateManager, cfg *state.Config, gitRepo string) bool {
+	rc := cfg.ForRepo(gitRepo)
+	if rc.MaxWorktrees == 0 && rc.MaxSizeGB == 0 {
+		return true
+	}
+
+	entries := stateManager.ListWorktreesByRepo(gitRepo)
+	var problems []string
+
+	if rc.MaxWorktrees > 0 && len(entries)+1 > rc.MaxWorktrees {
+		problems = append(problems, fmt.Sprintf("%s already has %d of at most %d worktrees", gitRepo, len(entries), rc.MaxWorktrees))
+	}
+
+	if rc.MaxSizeGB > 0 {
+		var total int64
+		for _, u := range measureWorktrees(entries) {
+			total += u.size
+		}
+		limit := int64(rc.MaxSizeGB * (1 << 30))
+		if total >= limit {
+			problems = append(problems, fmt.Sprintf("worktrees of %s use %s of at most %s", gitRepo, formatBytes(total), formatBytes(limit)))
+		}
+	}
+
+	if len(problems) == 0 {
+		return true
+	}
+
+	block := rc.QuotaMode == "block"
+	for _, problem := range problems {
+		if block {
+			fmt.Printf("Quota exceeded: %s\n", problem)
+		} else {
+			fmt.Printf("Warning: quota exceeded: %s\n", problem)
+		}
+	}
+	if block {
+		fmt.Println("Remove or prune worktrees first, or change the quota with 'config set'")
+	}
+	return !block
+}
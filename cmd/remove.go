@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -12,13 +13,50 @@ import (
 
 var removeBranch bool
 var forceRemove bool
+var removeSkipChecks bool
+var removeTrash bool
+var removeKill bool
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [branch-name]",
-	Short:   "Remove an existing worktree",
-	Aliases: []string{"rm"},
-	Args:    cobra.ExactArgs(1),
+	Use:   "remove [branch-name|pattern]...",
+	Short: "Remove existing worktrees",
+	Long: `Remove the worktree of a branch.
+
+Several branch names, glob patterns (e.g. 'feature/JIRA-12*') and the filter
+flags --repo, --all, --label, --older-than and --merged select many worktrees at
+once. Their removal plan is shown and confirmed once; repositories are then
+processed in parallel and a summary is printed at the end.
+
+Without any of these, a fuzzy finder over the managed worktrees is opened in
+which several worktrees can be marked with Tab.`,
+	Aliases:           []string{"rm"},
+	ValidArgsFunction: completeWorktrees,
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !hasRemoveFilters(cmd) {
+			entries, err := pickWorktrees("remove", true)
+			if errors.Is(err, errPickerCancelled) {
+				return
+			} else if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			// The chosen worktrees may belong to any repository outside one
+			if len(entries) > 1 {
+				removeEntries(entries)
+				return
+			}
+			gitRoot, err := groupByRepo(entries)[0].checkoutDir()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			removeOne(entries[0], gitRoot)
+			return
+		}
+		if isBulkRemove(cmd, args) {
+			removeMany(args)
+			return
+		}
 		branchName := args[0]
 
 		// Get the current Git repository root
@@ -42,7 +80,7 @@ var removeCmd = &cobra.Command{
 		if orgRepo == "" {
 			fmt.Printf("Could not parse organization/username and repository name from remote URL: %s\n", remoteURL)
 			return
-	}
+		}
 
 		// Initialize state manager
 		stateManager, err := state.NewStateManager()
@@ -51,75 +89,216 @@ var removeCmd = &cobra.Command{
 			return
 		}
 
-		// Try to get worktree from state first
-		entry, exists := stateManager.GetWorktree(orgRepo, branchName)
-		if !exists {
-			// Fall back to old behavior if not found in state
-			fmt.Printf("Worktree for branch '%s' not registered\n", branchName)
+		// Try to get worktree from state first; without a prompt to catch a
+		// surprising match, only the exact name will do
+		var entry state.WorktreeEntry
+		if removeYes {
+			entry, err = matchExactWorktree(branchName, stateManager.ListWorktreesByRepo(orgRepo), false)
+		} else {
+			entry, err = resolveWorktree(stateManager, orgRepo, branchName)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
+		removeOne(entry, gitRoot)
+	},
+}
 
-		worktreePath := entry.Path
+// removeOne removes the worktree of entry after checking that nothing is lost
+// and asking for confirmation. gitRoot is any checkout of its repository.
+func removeOne(entry state.WorktreeEntry, gitRoot string) {
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		fmt.Printf("Error initializing state manager: %v\n", err)
+		return
+	}
+	branchName := entry.BranchName
+
+	if removeBranch {
+		if err := checkBranchDeletion(entry, gitRoot); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
 
-		// Remove from state
-		err = stateManager.RemoveWorktree(orgRepo, branchName)
+	// Refuse to pull the directory out from under running processes
+	var procs []worktreeProcess
+	if !forceRemove {
+		procs, err = processesUsing(entry.Path)
 		if err != nil {
-			fmt.Printf("Error removing worktree from state: %v\n", err)
+			fmt.Printf("Error checking for processes using the worktree: %v\n", err)
+			return
+		}
+		if len(procs) > 0 && !removeKill {
+			fmt.Printf("Worktree at '%s' is in use by %d process(es):\n", entry.Path, len(procs))
+			printProcesses(procs)
+			fmt.Println("\nRefusing to remove. Use --kill to terminate them or --force to remove anyway.")
+			return
 		}
+	}
 
-		// Check if the worktree directory exists before attempting to remove
-		_, err = os.Stat(worktreePath)
-		if os.IsNotExist(err) {
-			fmt.Printf("Worktree for branch '%s' not found at '%s'\n", branchName, worktreePath)
+	// Refuse to destroy work that exists nowhere else; the trash keeps it
+	if !removeSkipChecks && !removeTrash {
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
 			return
-		} else if err != nil {
-			fmt.Printf("Error checking worktree path '%s': %v\n", worktreePath, err)
+		}
+		report, err := checkWorktree(entry, gitRoot, cfg.ForRepo(entry.GitRepo).Precious)
+		if err != nil {
+			fmt.Printf("Error checking worktree: %v\n", err)
 			return
 		}
+		if blockers := report.blockers(removeBranch); len(blockers) > 0 {
+			report.print()
+			fmt.Printf("\nRefusing to remove: %s would be lost. Use --skip-checks to remove anyway.\n", strings.Join(blockers, ", "))
+			return
+		}
+	}
+
+	question := fmt.Sprintf("Remove worktree at '%s'?", entry.Path)
+	switch {
+	case removeTrash:
+		question = fmt.Sprintf("Move worktree at '%s' to the trash?", entry.Path)
+	case removeBranch:
+		question = fmt.Sprintf("Remove worktree at '%s' and branch '%s'?", entry.Path, branchName)
+	}
+	if !removeYes && !confirm(question) {
+		fmt.Println("Aborted")
+		return
+	}
 
-		// Remove the worktree
-		removeArgs := []string{"worktree", "remove"}
-		if forceRemove {
-			removeArgs = append(removeArgs, "--force")
+	if len(procs) > 0 {
+		remaining, err := terminateProcesses(procs, entry.Path)
+		if err != nil {
+			fmt.Printf("Error terminating processes: %v\n", err)
+			return
+		}
+		if len(remaining) > 0 {
+			fmt.Printf("Worktree at '%s' is still in use by %d process(es):\n", entry.Path, len(remaining))
+			printProcesses(remaining)
+			fmt.Println("\nRefusing to remove. Use --force to remove anyway.")
+			return
 		}
-		removeArgs = append(removeArgs, worktreePath)
+	}
 
-		cmdRemoveWorktree := exec.Command("git", removeArgs...)
-		cmdRemoveWorktree.Dir = gitRoot // Ensure command runs in the git root
-		out, err := cmdRemoveWorktree.CombinedOutput()
+	if removeTrash {
+		id, err := trashWorktree(stateManager, entry, gitRoot, removeBranch)
 		if err != nil {
-			fmt.Printf("Error removing worktree at '%s': %v\nOutput: %s\n", worktreePath, err, out)
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
+		printDone("Moved worktree at '%s' to the trash; bring it back with 'restore %s'\n", entry.Path, id)
+		return
+	}
 
-		var successMsg string
-		if removeBranch {
-			branchRemoveArgs := []string{"branch"}
-			if forceRemove {
-				branchRemoveArgs = append(branchRemoveArgs, "-D") // Force delete branch
-			} else {
-				branchRemoveArgs = append(branchRemoveArgs, "-d") // Delete branch
-			}
-			branchRemoveArgs = append(branchRemoveArgs, branchName)
+	err = removeWorktree(stateManager, entry, gitRoot, removeOptions{force: forceRemove, deleteBranch: removeBranch})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 
-			cmdRemoveBranch := exec.Command("git", branchRemoveArgs...)
-			cmdRemoveBranch.Dir = gitRoot // Ensure command runs in the git root
-			out, err := cmdRemoveBranch.CombinedOutput()
-			if err != nil {
-				fmt.Printf("Error removing branch '%s': %v\nOutput: %s\n", branchName, err, out)
-				return
+	if removeBranch {
+		printDone("Successfully removed worktree at '%s' and branch '%s'\n", entry.Path, branchName)
+	} else {
+		printDone("Successfully removed worktree at '%s'\n", entry.Path)
+	}
+}
+
+// removeOptions controls how a worktree is removed
+type removeOptions struct {
+	force        bool // Remove the worktree even if it is dirty and force-delete the branch
+	deleteBranch bool // Also delete the worktree's local branch
+	forceBranch  bool // Delete the branch even if git does not consider it merged
+}
+
+// removeWorktree deletes the worktree of entry, unregisters it and optionally
+// deletes its branch.
+// gitRoot is any checkout of the repository and is used to run git commands.
+func removeWorktree(stateManager *state.StateManager, entry state.WorktreeEntry, gitRoot string, opts removeOptions) error {
+	worktreePath := entry.Path
+	if opts.deleteBranch {
+		if err := checkBranchDeletion(entry, gitRoot); err != nil {
+			return err
+		}
+	}
+
+	// Save uncommitted work where it outlives the worktree
+	if _, err := os.Stat(worktreePath); err == nil {
+		name, err := snapshotWorktree(entry, "Automatic snapshot before removing the worktree")
+		if err != nil {
+			// Only uncommitted changes that would be lost stop the removal; a
+			// worktree git cannot read must stay removable
+			if dirty, _ := isWorktreeDirty(worktreePath); dirty && !opts.force {
+				return fmt.Errorf("taking snapshot: %w", err)
 			}
-			successMsg = fmt.Sprintf("Successfully removed worktree at '%s' and branch '%s'", worktreePath, branchName)
+			fmt.Printf("Warning: could not take a snapshot of '%s': %v\n", worktreePath, err)
+		}
+		if name != "" {
+			fmt.Printf("Saved uncommitted changes as snapshot %s\n", name)
+		}
+	}
+
+	// Check if the worktree directory exists before attempting to remove
+	_, err := os.Stat(worktreePath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("worktree for branch '%s' not found at '%s'; unregister it with cleanup", entry.BranchName, worktreePath)
+	} else if err != nil {
+		return fmt.Errorf("checking worktree path '%s': %w", worktreePath, err)
+	}
+
+	// Remove the worktree
+	removeArgs := []string{"worktree", "remove"}
+	if opts.force {
+		removeArgs = append(removeArgs, "--force")
+	}
+	removeArgs = append(removeArgs, worktreePath)
+
+	planFile("delete", worktreePath)
+	out, err := gitChange(gitRoot, removeArgs...)
+	if err != nil {
+		return fmt.Errorf("removing worktree at '%s': %v\nOutput: %s", worktreePath, err, out)
+	}
+
+	// Unregister only once the worktree is gone, so a failure leaves it managed
+	if err := stateManager.RemoveWorktree(entry.GitRepo, entry.BranchName); err != nil {
+		return fmt.Errorf("removing worktree from state: %w", err)
+	}
+
+	if opts.deleteBranch {
+		branchRemoveArgs := []string{"branch"}
+		if opts.force || opts.forceBranch {
+			branchRemoveArgs = append(branchRemoveArgs, "-D") // Force delete branch
 		} else {
-			successMsg = fmt.Sprintf("Successfully removed worktree at '%s'", worktreePath)
+			branchRemoveArgs = append(branchRemoveArgs, "-d") // Delete branch
 		}
-		fmt.Println(successMsg)
-	},
+		branchRemoveArgs = append(branchRemoveArgs, entry.BranchName)
+
+		out, err := gitChange(gitRoot, branchRemoveArgs...)
+		if err != nil {
+			return fmt.Errorf("removing branch '%s': %v\nOutput: %s", entry.BranchName, err, out)
+		}
+	}
+
+	return nil
 }
 
 func init() {
 	removeCmd.Flags().BoolVarP(&removeBranch, "remove-branch", "b", false, "Also remove the associated Git branch")
-	removeCmd.Flags().BoolVarP(&forceRemove, "force", "f", false, "Force removal of the worktree and/or branch")
+	removeCmd.Flags().BoolVarP(&forceRemove, "force", "f", false, "Force removal of the worktree and/or branch, even while it is in use")
+	removeCmd.Flags().BoolVarP(&removeTrash, "trash", "t", false, "Move the worktree to the trash instead of deleting it, so it can be restored")
+	removeCmd.Flags().StringVar(&removeSelector.repo, "repo", "", "Remove worktrees of this repository (owner/repo); defaults to the current repository")
+	removeCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	removeCmd.Flags().BoolVarP(&removeSelector.all, "all", "a", false, "Select worktrees of all repositories")
+	removeCmd.Flags().StringSliceVar(&removeSelector.labels, "label", nil, "Only remove worktrees with this label (repeatable)")
+	removeCmd.RegisterFlagCompletionFunc("label", completeLabels)
+	removeCmd.Flags().StringVar(&removeOlderThan, "older-than", "", "Only remove worktrees created longer ago than this (e.g. 30d)")
+	removeCmd.Flags().BoolVar(&removeMerged, "merged", false, "Only remove worktrees whose branch was merged into the default branch")
+	removeCmd.Flags().BoolVarP(&removeYes, "yes", "y", false, "Do not ask for confirmation; branch names must then be exact or nicknames")
+	removeCmd.Flags().BoolVar(&removeKill, "kill", false, "Terminate processes that are using the worktree before removing it")
+	removeCmd.Flags().BoolVar(&removeSkipChecks, "skip-checks", false, "Remove even if untracked files, unpushed commits, stashes or an operation in progress would be lost")
 
 	// Add the worktree-dir flag to the remove command as well
 	defaultWorktreeDir := GetDefaultWorktreeDir()
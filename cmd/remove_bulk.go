@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	removeSelector  worktreeSelector
+	removeOlderThan string
+	removeMerged    bool
+	removeYes       bool
+)
+
+// removeFilterFlags select worktrees for bulk removal
+var removeFilterFlags = []string{"repo", "all", "label", "older-than", "merged"}
+
+// removeTarget is a worktree selected for bulk removal
+type removeTarget struct {
+	entry   state.WorktreeEntry
+	gitRoot string // Checkout used to run git commands for the repository
+	skip    string // Why the worktree is kept, empty if it will be removed
+	result  string
+	err     error
+}
+
+// isBulkRemove reports whether remove was asked for anything but a single branch name
+func isBulkRemove(cmd *cobra.Command, args []string) bool {
+	return len(args) > 1 || (len(args) == 1 && strings.ContainsAny(args[0], "*?[")) || hasRemoveFilters(cmd)
+}
+
+// branchRegistered reports whether any of entries is a worktree of branch
+func branchRegistered(entries []state.WorktreeEntry, branch string) bool {
+	for _, entry := range entries {
+		if entry.BranchName == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRemoveFilters reports whether any of the filter flags was given
+func hasRemoveFilters(cmd *cobra.Command) bool {
+	for _, name := range removeFilterFlags {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeMany removes every worktree matching the branch names or patterns and
+// the filter flags. The plan is shown and confirmed once. Repositories are
+// processed in parallel, the worktrees of one repository one after another.
+func removeMany(patterns []string) {
+	removeSelector.globs = patterns
+	if err := removeSelector.validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := removeSelector.resolveRepo(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	var olderThan time.Duration
+	if removeOlderThan != "" {
+		var err error
+		olderThan, err = parseAge(removeOlderThan)
+		if err != nil {
+			fmt.Printf("Error parsing --older-than: %v\n", err)
+			return
+		}
+	}
+
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		fmt.Printf("Error initializing state manager: %v\n", err)
+		return
+	}
+
+	// Names other than patterns are resolved like a single branch name would be
+	candidates := worktreeSelector{repo: removeSelector.repo, all: removeSelector.all, labels: removeSelector.labels}.selectFrom(stateManager)
+	for i, pattern := range patterns {
+		// An exact branch name may select it in several repositories
+		if strings.ContainsAny(pattern, "*?[") || branchRegistered(candidates, pattern) {
+			continue
+		}
+		match := matchWorktree
+		if removeYes {
+			match = matchExactWorktree // The plan is not confirmed
+		}
+		entry, err := match(pattern, candidates, false)
+		if errors.Is(err, errNotRegistered) {
+			continue // Reported below
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		patterns[i] = entry.BranchName
+	}
+	removeSelector.globs = patterns
+
+	selected := removeSelector.selectFrom(stateManager)
+	for _, pattern := range patterns {
+		matched := false
+		for _, entry := range selected {
+			if (worktreeSelector{globs: []string{pattern}}).matches(entry) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fmt.Printf("No registered worktree matches '%s'\n", pattern)
+		}
+	}
+
+	var entries []state.WorktreeEntry
+	for _, entry := range selected {
+		if olderThan == 0 || time.Since(entry.CreatedAt) >= olderThan {
+			entries = append(entries, entry)
+		}
+	}
+	removeEntries(entries)
+}
+
+// removeEntries shows the removal plan for entries, confirms it once and
+// removes them, repositories in parallel
+func removeEntries(entries []state.WorktreeEntry) {
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		fmt.Printf("Error initializing state manager: %v\n", err)
+		return
+	}
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		return
+	}
+
+	var groups [][]*removeTarget
+	for _, group := range groupByRepo(entries) {
+		gitRoot, err := group.checkoutDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		base := ""
+		if removeMerged {
+			if base, err = defaultBranchRef(gitRoot); err != nil {
+				fmt.Printf("Error checking %s: %v\n", group.repo, err)
+				continue
+			}
+		}
+
+		sortListRows(group.rows, "branch")
+		var targets []*removeTarget
+		for _, row := range group.rows {
+			if removeMerged {
+				reason := branchFinished(gitRoot, row.entry, base)
+				if !isMergeReason(reason) {
+					continue
+				}
+			}
+			target := &removeTarget{entry: row.entry, gitRoot: gitRoot}
+			target.skip = planRemoval(row.entry, gitRoot, cfg.ForRepo(group.repo))
+			targets = append(targets, target)
+		}
+		if len(targets) > 0 {
+			groups = append(groups, targets)
+		}
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("Nothing to remove")
+		return
+	}
+
+	action := "remove worktree"
+	switch {
+	case removeTrash:
+		action = "move to trash"
+	case removeBranch:
+		action = "remove worktree and branch"
+	}
+	fmt.Println("Remove plan:")
+	t := newTable("BRANCH", "REPO", "PATH", "ACTION")
+	t.flex = 2
+	toRemove := 0
+	for _, targets := range groups {
+		for _, target := range targets {
+			if target.skip != "" {
+				t.addRow(target.entry.BranchName, target.entry.GitRepo, target.entry.Path, "skip ("+target.skip+")")
+				continue
+			}
+			t.addRow(target.entry.BranchName, target.entry.GitRepo, target.entry.Path, action)
+			toRemove++
+		}
+	}
+	t.render(os.Stdout, nil, terminalWidth())
+	fmt.Println()
+
+	if toRemove == 0 {
+		fmt.Println("Nothing to remove")
+		return
+	}
+	if !removeYes && !confirm(fmt.Sprintf("Remove %d worktree(s)?", toRemove)) {
+		fmt.Println("Aborted")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, targets := range groups {
+		wg.Add(1)
+		go func(targets []*removeTarget) {
+			defer wg.Done()
+			for _, target := range targets {
+				if target.skip == "" {
+					target.result, target.err = executeRemoval(stateManager, target)
+				}
+			}
+		}(targets)
+	}
+	wg.Wait()
+
+	summary := newTable("BRANCH", "REPO", "RESULT")
+	removed, failed, skipped := 0, 0, 0
+	for _, targets := range groups {
+		for _, target := range targets {
+			switch {
+			case target.skip != "":
+				skipped++
+			case target.err != nil:
+				summary.addRow(target.entry.BranchName, target.entry.GitRepo, "failed: "+strings.Join(strings.Fields(target.err.Error()), " "))
+				failed++
+			default:
+				summary.addRow(target.entry.BranchName, target.entry.GitRepo, target.result)
+				removed++
+			}
+		}
+	}
+	summary.render(os.Stdout, nil, terminalWidth())
+	fmt.Printf("\nRemoved %d, failed %d, skipped %d\n", removed, failed, skipped)
+}
+
+// planRemoval runs the pre-removal checks for entry and returns why it has to
+// be skipped, or "" if it can be removed
+func planRemoval(entry state.WorktreeEntry, gitRoot string, rc state.RepoConfig) string {
+	if removeBranch {
+		if reason := protectedBranch(rc.Protected, gitRoot, entry.BranchName); reason != "" {
+			return "protected branch, " + reason
+		}
+	}
+	if !removeSkipChecks && !removeTrash {
+		report, err := checkWorktree(entry, gitRoot, rc.Precious)
+		if err != nil {
+			return "cannot check worktree"
+		}
+		if blockers := report.blockers(removeBranch); len(blockers) > 0 {
+			return "would lose " + strings.Join(blockers, ", ")
+		}
+	}
+	if !forceRemove && !removeKill {
+		if procs, _ := processesUsing(entry.Path); len(procs) > 0 {
+			return fmt.Sprintf("in use by %d process(es)", len(procs))
+		}
+	}
+	return ""
+}
+
+// executeRemoval removes or trashes the worktree of target and describes the outcome
+func executeRemoval(stateManager *state.StateManager, target *removeTarget) (string, error) {
+	entry := target.entry
+	if removeKill && !forceRemove {
+		if procs, _ := processesUsing(entry.Path); len(procs) > 0 {
+			remaining, err := terminateProcesses(procs, entry.Path)
+			if err != nil {
+				return "", err
+			}
+			if len(remaining) > 0 {
+				return "", fmt.Errorf("still in use by %d process(es)", len(remaining))
+			}
+		}
+	}
+
+	if removeTrash {
+		id, err := trashWorktree(stateManager, entry, target.gitRoot, removeBranch)
+		if err != nil {
+			return "", err
+		}
+		return "trashed as " + id, nil
+	}
+
+	opts := removeOptions{force: forceRemove, deleteBranch: removeBranch, forceBranch: removeMerged}
+	if err := removeWorktree(stateManager, entry, target.gitRoot, opts); err != nil {
+		return "", err
+	}
+	if removeBranch {
+		return "removed with branch", nil
+	}
+	return "removed", nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// errNotRegistered is returned when a name matches no registered worktree
+var errNotRegistered = errors.New("not registered")
+
+// ambiguousError is returned when a name matches several worktrees equally well
+type ambiguousError struct {
+	name       string
+	candidates []string
+}
+
+func (e *ambiguousError) Error() string {
+	return fmt.Sprintf("'%s' matches %d worktrees, be more specific:\n  %s", e.name, len(e.candidates), strings.Join(e.candidates, "\n  "))
+}
+
+// resolveWorktree finds the registered worktree of repo that name refers to
+// and records the access. An empty repo searches every repository, where
+// worktrees can also be named by their ID (owner/repo/branch).
+func resolveWorktree(stateManager *state.StateManager, repo, name string) (state.WorktreeEntry, error) {
+	var entries []state.WorktreeEntry
+	if repo == "" {
+		entries = stateManager.ListWorktrees()
+	} else {
+		entries = stateManager.ListWorktreesByRepo(repo)
+	}
+	entry, err := matchWorktree(name, entries, repo == "")
+	if err != nil {
+		return state.WorktreeEntry{}, err
+	}
+	entry, _ = stateManager.GetWorktree(entry.GitRepo, entry.BranchName) // Record the access
+	return entry, nil
+}
+
+// resolveBranchName returns the branch of the registered worktree of the
+// current repository that name refers to, or name itself if it refers to none;
+// snapshots outlive their worktrees
+func resolveBranchName(name string) (string, error) {
+	repo, err := currentRepoName()
+	if err != nil {
+		return name, nil
+	}
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return "", fmt.Errorf("initializing state manager: %w", err)
+	}
+	entry, err := matchWorktree(name, stateManager.ListWorktreesByRepo(repo), false)
+	if errors.Is(err, errNotRegistered) {
+		return name, nil
+	} else if err != nil {
+		return "", err
+	}
+	return entry.BranchName, nil
+}
+
+// matchWorktree finds the entry that name refers to. In order of preference
+// name is the exact branch name (or ID if qualified), an alias, a prefix of the
+// branch name or an alias, or a case-insensitive substring of either, such as a
+// ticket number. The first of these that matches anything decides: a single
+// match is returned, several are reported as ambiguous.
+func matchWorktree(name string, entries []state.WorktreeEntry, qualified bool) (state.WorktreeEntry, error) {
+	return findWorktree(name, entries, qualified, false)
+}
+
+// matchExactWorktree is matchWorktree for changes that are not confirmed
+// interactively: name must be the exact branch name (or ID) or an alias. A name
+// that only partly matches a worktree is refused with the full name to use.
+func matchExactWorktree(name string, entries []state.WorktreeEntry, qualified bool) (state.WorktreeEntry, error) {
+	entry, err := findWorktree(name, entries, qualified, true)
+	if !errors.Is(err, errNotRegistered) {
+		return entry, err
+	}
+	partial, partialErr := findWorktree(name, entries, qualified, false)
+	if partialErr != nil {
+		return state.WorktreeEntry{}, err
+	}
+	full := partial.BranchName
+	if qualified {
+		full = partial.ID
+	}
+	return state.WorktreeEntry{}, fmt.Errorf("'%s' only partly matches the worktree for branch '%s'; name it exactly as '%s' when not confirming", name, partial.BranchName, full)
+}
+
+// findWorktree implements matchWorktree; exact stops before prefix and
+// substring matches
+func findWorktree(name string, entries []state.WorktreeEntry, qualified, exact bool) (state.WorktreeEntry, error) {
+	lower := strings.ToLower(name)
+	tiers := []func(entry state.WorktreeEntry) bool{
+		func(entry state.WorktreeEntry) bool {
+			return entry.BranchName == name || (qualified && entry.ID == name)
+		},
+		func(entry state.WorktreeEntry) bool {
+			return entry.HasAlias(name)
+		},
+		func(entry state.WorktreeEntry) bool {
+			return anyName(entry, qualified, func(s string) bool { return strings.HasPrefix(s, name) })
+		},
+		func(entry state.WorktreeEntry) bool {
+			return anyName(entry, qualified, func(s string) bool { return strings.Contains(strings.ToLower(s), lower) })
+		},
+	}
+
+	if exact {
+		tiers = tiers[:2]
+	}
+
+	for _, matches := range tiers {
+		var found []state.WorktreeEntry
+		for _, entry := range entries {
+			if matches(entry) {
+				found = append(found, entry)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			return state.WorktreeEntry{}, &ambiguousError{name: name, candidates: describeCandidates(found, qualified)}
+		}
+	}
+	return state.WorktreeEntry{}, fmt.Errorf("worktree for branch '%s' %w", name, errNotRegistered)
+}
+
+// anyName reports whether the branch name, an alias or, if qualified, the ID of
+// entry satisfies match
+func anyName(entry state.WorktreeEntry, qualified bool, match func(string) bool) bool {
+	if match(entry.BranchName) || (qualified && match(entry.ID)) {
+		return true
+	}
+	for _, alias := range entry.Aliases {
+		if match(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsEntry reports whether entries contains the entry with the given ID
+func containsEntry(entries []state.WorktreeEntry, id string) bool {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// describeCandidates lists the matching worktrees by branch name (or ID if
+// qualified) with their aliases
+func describeCandidates(entries []state.WorktreeEntry, qualified bool) []string {
+	var candidates []string
+	for _, entry := range entries {
+		candidate := entry.BranchName
+		if qualified {
+			candidate = entry.ID
+		}
+		if len(entry.Aliases) > 0 {
+			candidate += " (" + strings.Join(entry.Aliases, ", ") + ")"
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
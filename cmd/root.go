@@ -33,6 +33,20 @@ func init() {
 		defaultWorktreeDir = envVar
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show the changes a command would make without making them")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Format of the --dry-run plan: text or json")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if dryRun {
+			return startDryRun(cmd)
+		}
+		return nil
+	}
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if plan != nil {
+			finishDryRun()
+		}
+	}
+
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(removeCmd)
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [branch-name] [command] [args...]",
+	Short: "Run a command in a worktree",
+	Long: `Run a command with the worktree of a branch as its working directory and
+exit with its exit status. The branch is named like for switch, including '-'
+and '@main'. Signals sent to git-worktree-manager are passed on to the command.
+
+The command runs with ` + envWorktreePath + `, ` + envBranch + ` and ` + envRepo + ` set in its
+environment, and with ` + envDepth + ` counting how many shells and commands started
+by git-worktree-manager it runs in.`,
+	Example: `  git-worktree-manager run feature/login make test
+  git-worktree-manager run @main -- git log -1 --oneline`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeSwitchTarget,
+	Run: func(cmd *cobra.Command, args []string) {
+		command := args[1:]
+		if command[0] == "--" {
+			command = command[1:]
+		}
+		if len(command) == 0 {
+			fmt.Println("Error: no command given")
+			os.Exit(1)
+		}
+		if code := switchTo(cmd, args[:1], command); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+func init() {
+	// Flags after the branch name belong to the command
+	runCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(runCmd)
+}
+
+// runForeground runs command in dir with env, connected to the terminal, and
+// returns its exit status. A command killed by a signal yields 128 plus the
+// signal number, like in a shell.
+func runForeground(dir string, env []string, command []string) (int, error) {
+	child := exec.Command(command[0], command[1:]...)
+	child.Dir = dir
+	child.Env = env
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	if err := child.Start(); err != nil {
+		return 127, err
+	}
+
+	// Signals typed at the terminal reach the child directly as it shares our
+	// process group; others are passed on. Either way we wait for it to exit.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	go func() {
+		for sig := range signals {
+			if interactive && (sig == os.Interrupt || sig == syscall.SIGQUIT) {
+				continue
+			}
+			child.Process.Signal(sig)
+		}
+	}()
+	err := child.Wait()
+	signal.Stop(signals)
+	close(signals)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal()), nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// nestingDepth returns how many shells and commands started by
+// git-worktree-manager the current process runs in
+func nestingDepth() int {
+	depth, _ := strconv.Atoi(os.Getenv(envDepth))
+	return depth
+}
+
+// worktreeEnvAt returns the environment for a command running in the worktree
+// at path, which need not be registered, e.g. the primary checkout
+func worktreeEnvAt(path string) []string {
+	if stateManager, err := state.NewStateManager(); err == nil {
+		if entry, found := stateManager.WorktreeAt(path); found {
+			return worktreeEnv(entry)
+		}
+	}
+	entry := state.WorktreeEntry{Path: path}
+	if remoteURL, err := gitOutput(path, "config", "--get", "remote.origin.url"); err == nil {
+		entry.GitRepo = ParseRemoteURL(remoteURL)
+	}
+	entry.BranchName, _ = gitOutput(path, "symbolic-ref", "--quiet", "--short", "HEAD")
+	return worktreeEnv(entry)
+}
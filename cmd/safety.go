@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// defaultPreciousPatterns match ignored files that usually hold local secrets or
+// settings which cannot be recreated from the repository
+var defaultPreciousPatterns = []string{".env", ".env.*", ".envrc", "*.pem", "*.key"}
+
+// gitOperations are the files git keeps in a worktree's gitdir while an
+// operation is in progress
+var gitOperations = []struct{ marker, name string }{
+	{"rebase-merge", "rebase"},
+	{"rebase-apply", "rebase"},
+	{"MERGE_HEAD", "merge"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+	{"BISECT_LOG", "bisect"},
+}
+
+// safetyReport lists the work that would be lost by removing a worktree
+type safetyReport struct {
+	entry     state.WorktreeEntry
+	missing   bool     // The worktree directory does not exist; only branch checks ran
+	changes   []string // Modified or staged files
+	untracked []string
+	precious  []string // Ignored files matching a precious pattern
+	unpushed  []string // Commits of the branch that are not on any remote, one line each
+	stashes   []string
+	operation string // Rebase, merge, ... in progress
+}
+
+// checkWorktree analyses the worktree of entry. gitRoot is any checkout of the
+// repository, used when the worktree itself is gone.
+func checkWorktree(entry state.WorktreeEntry, gitRoot string, precious []string) (*safetyReport, error) {
+	if len(precious) == 0 {
+		precious = defaultPreciousPatterns
+	}
+	report := &safetyReport{entry: entry}
+
+	dir := entry.Path
+	if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+		report.missing = true
+		dir = gitRoot
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !report.missing {
+		status, err := gitOutput(dir, "status", "--porcelain", "--untracked-files=all")
+		if err != nil {
+			return nil, fmt.Errorf("reading status: %w", err)
+		}
+		for _, line := range splitLines(status) {
+			// Lines are "XY path"; the output is trimmed, so X may be gone on the first line
+			fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+			if len(fields) != 2 {
+				continue
+			}
+			if fields[0] == "??" {
+				report.untracked = append(report.untracked, strings.TrimSpace(fields[1]))
+			} else {
+				report.changes = append(report.changes, strings.TrimSpace(fields[1]))
+			}
+		}
+
+		ignored, err := gitOutput(dir, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory")
+		if err != nil {
+			return nil, fmt.Errorf("listing ignored files: %w", err)
+		}
+		for _, file := range splitLines(ignored) {
+			name := filepath.Base(strings.TrimSuffix(file, "/"))
+			for _, pattern := range precious {
+				if ok, _ := filepath.Match(pattern, name); ok {
+					report.precious = append(report.precious, file)
+					break
+				}
+			}
+		}
+
+		if gitDir, err := gitOutput(dir, "rev-parse", "--path-format=absolute", "--git-dir"); err == nil {
+			for _, op := range gitOperations {
+				if _, err := os.Stat(filepath.Join(gitDir, op.marker)); err == nil {
+					report.operation = op.name
+					break
+				}
+			}
+		}
+	}
+
+	// Commits only this branch has; a detached worktree is checked at its HEAD
+	rev := "HEAD"
+	if report.missing {
+		rev = "refs/heads/" + entry.BranchName
+	}
+	if _, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", rev); err == nil {
+		unpushed, err := gitOutput(dir, "log", "--format=%h %s", rev, "--not", "--remotes")
+		if err != nil {
+			return nil, fmt.Errorf("listing unpushed commits: %w", err)
+		}
+		report.unpushed = splitLines(unpushed)
+	}
+
+	// Stashes are shared by all worktrees; their message names the branch they came from
+	stashes, err := gitOutput(dir, "stash", "list", "--format=%gd: %gs")
+	if err == nil {
+		for _, stash := range splitLines(stashes) {
+			if strings.Contains(stash, ": WIP on "+entry.BranchName+": ") || strings.Contains(stash, ": On "+entry.BranchName+": ") {
+				report.stashes = append(report.stashes, stash)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// blockers summarises what removal would destroy. Unpushed commits only count
+// when the branch is deleted too; otherwise they stay on the branch.
+func (r *safetyReport) blockers(deleteBranch bool) []string {
+	var blockers []string
+	if len(r.changes) > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d uncommitted change(s)", len(r.changes)))
+	}
+	if len(r.untracked) > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d untracked file(s)", len(r.untracked)))
+	}
+	if len(r.precious) > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d precious ignored file(s)", len(r.precious)))
+	}
+	if deleteBranch && len(r.unpushed) > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d unpushed commit(s)", len(r.unpushed)))
+	}
+	if len(r.stashes) > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d stash(es)", len(r.stashes)))
+	}
+	if r.operation != "" {
+		blockers = append(blockers, r.operation+" in progress")
+	}
+	return blockers
+}
+
+// print writes the detailed report, listing at most a few items per section
+func (r *safetyReport) print() {
+	const maxItems = 10
+
+	fmt.Printf("%s (%s)\n", r.entry.BranchName, r.entry.Path)
+	if r.missing {
+		fmt.Println("  Worktree directory is missing; only the branch was checked")
+	}
+
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("  %s:\n", title)
+		for i, item := range items {
+			if i == maxItems {
+				fmt.Printf("    ... and %d more\n", len(items)-maxItems)
+				break
+			}
+			fmt.Printf("    %s\n", item)
+		}
+	}
+	if r.operation != "" {
+		fmt.Printf("  A %s is in progress\n", r.operation)
+	}
+	section("Uncommitted changes", r.changes)
+	section("Untracked files", r.untracked)
+	section("Precious ignored files", r.precious)
+	section("Commits not on any remote", r.unpushed)
+	section("Stashes", r.stashes)
+
+	if len(r.blockers(true)) == 0 {
+		fmt.Println("  Nothing would be lost")
+	}
+}
+
+// splitLines splits command output into lines, returning nil for empty output
+func splitLines(output string) []string {
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
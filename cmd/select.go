@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+)
+
+// worktreeSelector picks managed worktrees by repository, label and branch pattern
+type worktreeSelector struct {
+	repo   string   // Repository (owner/repo); empty means the current repository
+	all    bool     // Select from every repository instead of a single one
+	labels []string // Labels an entry must carry, all of them
+	globs  []string // Branch patterns, any of which must match (path.Match syntax)
+}
+
+// resolveRepo fills in the current repository when neither a repository nor all
+// repositories were requested
+func (s *worktreeSelector) resolveRepo() error {
+	if s.all || s.repo != "" {
+		return nil
+	}
+	repo, err := currentRepoName()
+	if err != nil {
+		return fmt.Errorf("not inside a repository with a recognised origin remote (use --repo or --all): %w", err)
+	}
+	s.repo = repo
+	return nil
+}
+
+// validate checks that all branch patterns are well formed
+func (s worktreeSelector) validate() error {
+	for _, glob := range s.globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether entry satisfies the selector
+func (s worktreeSelector) matches(entry state.WorktreeEntry) bool {
+	if !s.all && s.repo != "" && entry.GitRepo != s.repo {
+		return false
+	}
+	for _, label := range s.labels {
+		if !entry.HasLabel(label) {
+			return false
+		}
+	}
+	if len(s.globs) == 0 {
+		return true
+	}
+	for _, glob := range s.globs {
+		if ok, _ := path.Match(glob, entry.BranchName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFrom returns the matching entries ordered by repository and branch
+func (s worktreeSelector) selectFrom(stateManager *state.StateManager) []state.WorktreeEntry {
+	var selected []state.WorktreeEntry
+	for _, entry := range stateManager.ListWorktrees() {
+		if s.matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].GitRepo != selected[j].GitRepo {
+			return selected[i].GitRepo < selected[j].GitRepo
+		}
+		return selected[i].BranchName < selected[j].BranchName
+	})
+	return selected
+}
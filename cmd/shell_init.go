@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cdFileEnv names the file in which the shell wrapper expects the directory to change to
+const cdFileEnv = "GWM_CD_FILE"
+
+var shellInitName string
+
+// shellWrappers define a function that runs the binary and then changes into
+// the directory it left in the cd file. NAME and BIN are substituted.
+var shellWrappers = map[string]string{
+	"bash": posixWrapper,
+	"zsh":  posixWrapper,
+	"fish": `function NAME --description 'git-worktree-manager, changing directory on switch and create'
+    set -l cd_file (mktemp)
+    or return
+    env ` + cdFileEnv + `=$cd_file BIN $argv
+    set -l ret $status
+    if test -s $cd_file
+        cd (cat $cd_file)
+        or set ret $status
+    end
+    rm -f $cd_file
+    return $ret
+end
+`,
+}
+
+const posixWrapper = `NAME() {
+  local cd_file ret
+  cd_file="$(mktemp)" || return
+  ` + cdFileEnv + `="$cd_file" command BIN "$@"
+  ret=$?
+  if [ -s "$cd_file" ]; then
+    cd -- "$(cat "$cd_file")" || ret=$?
+  fi
+  rm -f "$cd_file"
+  return $ret
+}
+`
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init [bash|zsh|fish]",
+	Short: "Print a shell function that lets switch and create change directory",
+	Long: `Print a shell function wrapping git-worktree-manager. Through the wrapper,
+switch and create change the directory of the current shell instead of starting
+a new shell in the worktree.
+
+Add one of these lines to your shell's startup file:
+
+  eval "$(git-worktree-manager shell-init bash)"   # ~/.bashrc
+  eval "$(git-worktree-manager shell-init zsh)"    # ~/.zshrc
+  git-worktree-manager shell-init fish | source    # ~/.config/fish/config.fish
+
+Use --name to give the function a shorter name, e.g. --name gwm.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Run: func(cmd *cobra.Command, args []string) {
+		wrapper, ok := shellWrappers[args[0]]
+		if !ok {
+			fmt.Printf("Error: unsupported shell '%s' (available: bash, zsh, fish)\n", args[0])
+			return
+		}
+		fmt.Print(strings.NewReplacer("NAME", shellInitName, "BIN", rootCmd.Name()).Replace(wrapper))
+	},
+}
+
+func init() {
+	shellInitCmd.Flags().StringVar(&shellInitName, "name", rootCmd.Name(), "Name of the shell function")
+	rootCmd.AddCommand(shellInitCmd)
+}
+
+// changeDirectory hands dir to the shell wrapper, which changes into it once
+// this command exits. Returns false when not running under the wrapper.
+func changeDirectory(dir string) bool {
+	cdFile := os.Getenv(cdFileEnv)
+	if cdFile == "" {
+		return false
+	}
+	if err := os.WriteFile(cdFile, []byte(dir), 0600); err != nil {
+		fmt.Printf("Error passing the directory to the shell: %v\n", err)
+		return false
+	}
+	return true
+}
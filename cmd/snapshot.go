@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// snapshotRefPrefix is the namespace of snapshot refs, followed by <branch>/<timestamp>
+const snapshotRefPrefix = "refs/worktree-manager/snapshots/"
+
+// defaultSnapshotKeep is the number of snapshots kept per branch unless configured otherwise
+const defaultSnapshotKeep = 10
+
+var (
+	snapshotMessage   string
+	snapshotKeep      int
+	snapshotOlderThan string
+)
+
+// snapshot is a snapshot ref as listed by 'snapshot list'
+type snapshot struct {
+	ref     string
+	name    string // <branch>/<timestamp>
+	branch  string
+	commit  string
+	created time.Time
+	subject string
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [branch-name]",
+	Short: "Save the uncommitted changes of a worktree without touching it",
+	Long: `Record the full state of a worktree, including staged, unstaged and untracked
+files, as a commit under refs/worktree-manager/snapshots/<branch>/<timestamp>.
+The index and the working tree are left untouched.
+
+Without a branch name the worktree in the current directory is saved.
+remove and prune take a snapshot automatically before deleting a worktree with
+uncommitted changes. Old snapshots are deleted according to the snapshot_keep
+and snapshot_ttl settings (see 'config set').`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := snapshotTarget(args)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		message := snapshotMessage
+		if message == "" {
+			message = "Snapshot of " + entry.BranchName
+		}
+		name, err := snapshotWorktree(entry, message)
+		if err != nil {
+			fmt.Printf("Error taking snapshot: %v\n", err)
+			return
+		}
+		if name == "" {
+			if plan == nil {
+				fmt.Println("No uncommitted changes to snapshot")
+			}
+			return
+		}
+		fmt.Printf("Saved snapshot %s\n", name)
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:               "list [branch-name]",
+	Short:             "List the snapshots of the current repository",
+	Aliases:           []string{"ls"},
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktree,
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshots, err := listSnapshots("", "")
+		if err != nil {
+			fmt.Printf("Error listing snapshots: %v\n", err)
+			return
+		}
+		if len(args) > 0 {
+			// A branch with snapshots is taken as is, its worktree may be gone
+			branch := args[0]
+			if !containsSnapshotBranch(snapshots, branch) {
+				if branch, err = resolveBranchName(branch); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+			}
+			if snapshots, err = listSnapshots("", branch); err != nil {
+				fmt.Printf("Error listing snapshots: %v\n", err)
+				return
+			}
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots")
+			return
+		}
+
+		t := newTable("SNAPSHOT", "AGE", "COMMIT", "MESSAGE")
+		for _, s := range snapshots {
+			t.addRow(s.name, formatAge(time.Since(s.created)), s.commit, s.subject)
+		}
+		t.render(os.Stdout, nil, terminalWidth())
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot|branch-name]",
+	Short: "Restore the files of a snapshot into its branch's worktree",
+	Long: `Write the files recorded in a snapshot back into the worktree of its branch.
+A branch name restores the branch's latest snapshot. Uncommitted changes in the
+worktree are saved as a new snapshot first, so nothing is lost.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		snap, err := findSnapshot(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		entry, err := snapshotTarget([]string{snap.branch})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Keep whatever is in the worktree now
+		saved, err := snapshotWorktree(entry, "Automatic snapshot before restoring "+snap.name)
+		if err != nil {
+			fmt.Printf("Error taking snapshot: %v\n", err)
+			return
+		}
+		if saved != "" {
+			fmt.Printf("Saved current changes as %s\n", saved)
+		}
+
+		head, _ := gitOutput(entry.Path, "rev-parse", "HEAD")
+		parent, _ := gitOutput(entry.Path, "rev-parse", snap.ref+"^")
+		if out, err := gitChange(entry.Path, "restore", "--source="+snap.ref, "--worktree", "--", "."); err != nil {
+			fmt.Printf("Error restoring snapshot: %v\nOutput: %s\n", err, out)
+			return
+		}
+		printDone("Restored snapshot %s into '%s'\n", snap.name, entry.Path)
+		if head != parent {
+			fmt.Println("Note: the snapshot was taken on a different commit; review the changes with git diff")
+		}
+	},
+}
+
+var snapshotPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old snapshots of the current repository",
+	Long: `Delete snapshots beyond the configured number per branch (snapshot_keep) and
+snapshots older than snapshot_ttl. --keep and --older-than override the settings.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, err := currentRepoName()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		cfg, err := state.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading configuration: %v\n", err)
+			return
+		}
+		rc := cfg.ForRepo(repo)
+		if snapshotKeep > 0 {
+			rc.SnapshotKeep = snapshotKeep
+		}
+		if snapshotOlderThan != "" {
+			rc.SnapshotTTL = snapshotOlderThan
+		}
+
+		snapshots, err := listSnapshots("", "")
+		if err != nil {
+			fmt.Printf("Error listing snapshots: %v\n", err)
+			return
+		}
+		deleted, err := pruneSnapshots("", snapshots, rc)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		fmt.Printf("Deleted %d of %d snapshot(s)\n", deleted, len(snapshots))
+	},
+}
+
+func init() {
+	snapshotCmd.Flags().StringVarP(&snapshotMessage, "message", "m", "", "Description of the snapshot")
+	snapshotPruneCmd.Flags().IntVar(&snapshotKeep, "keep", 0, "Number of snapshots to keep per branch")
+	snapshotPruneCmd.Flags().StringVar(&snapshotOlderThan, "older-than", "", "Delete snapshots older than this (e.g. 30d)")
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotPruneCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+// snapshotTarget returns the worktree a snapshot command works on: the
+// registered worktree of the branch in args, or the worktree in the current directory
+func snapshotTarget(args []string) (state.WorktreeEntry, error) {
+	repo, err := currentRepoName()
+	if err != nil {
+		return state.WorktreeEntry{}, err
+	}
+
+	if len(args) > 0 {
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			return state.WorktreeEntry{}, fmt.Errorf("initializing state manager: %w", err)
+		}
+		entry, err := resolveWorktree(stateManager, repo, args[0])
+		if err == nil {
+			return entry, nil
+		} else if !errors.Is(err, errNotRegistered) {
+			return state.WorktreeEntry{}, err
+		}
+	}
+
+	toplevel, err := gitOutput("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return state.WorktreeEntry{}, fmt.Errorf("not inside a worktree")
+	}
+	branch, err := gitOutput("", "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		short, _ := gitOutput("", "rev-parse", "--short", "HEAD")
+		branch = "detached-" + short
+	}
+	if len(args) > 0 && args[0] != branch {
+		return state.WorktreeEntry{}, fmt.Errorf("no worktree for branch '%s' is registered", args[0])
+	}
+	return state.WorktreeEntry{Path: toplevel, GitRepo: repo, BranchName: branch}, nil
+}
+
+// snapshotWorktree records the uncommitted state of the worktree of entry as
+// a commit on top of HEAD and applies the retention policy to the branch's
+// snapshots. It returns the snapshot's name, or "" if there was nothing to save.
+func snapshotWorktree(entry state.WorktreeEntry, message string) (string, error) {
+	dir := entry.Path
+	head, err := gitOutput(dir, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("reading HEAD: %w", err)
+	}
+
+	// Taking a snapshot writes objects, so in dry-run mode it is only recorded
+	if plan != nil {
+		if dirty, _ := isWorktreeDirty(dir); dirty {
+			plan.add(planStep{Kind: "git", Action: "snapshot", Target: snapshotRefPrefix + entry.BranchName + "/<timestamp>", Dir: dir})
+		}
+		return "", nil
+	}
+
+	// Build the tree in a temporary index so the real one stays untouched
+	index, err := os.CreateTemp("", "gwm-snapshot-index-*")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+	env := append(os.Environ(), "GIT_INDEX_FILE="+index.Name())
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	if _, err := run("read-tree", "HEAD"); err != nil {
+		return "", err
+	}
+	if _, err := run("add", "--all"); err != nil {
+		return "", err
+	}
+	tree, err := run("write-tree")
+	if err != nil {
+		return "", err
+	}
+	if headTree, err := gitOutput(dir, "rev-parse", "HEAD^{tree}"); err == nil && headTree == tree {
+		return "", nil
+	}
+	commit, err := run("commit-tree", tree, "-p", head, "-m", message)
+	if err != nil {
+		return "", err
+	}
+
+	name := entry.BranchName + "/" + time.Now().Format("20060102-150405")
+	for i := 2; ; i++ {
+		if _, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", snapshotRefPrefix+name); err != nil {
+			break
+		}
+		name = fmt.Sprintf("%s/%s-%d", entry.BranchName, time.Now().Format("20060102-150405"), i)
+	}
+	if out, err := gitCombinedOutput(dir, "update-ref", snapshotRefPrefix+name, commit); err != nil {
+		return "", fmt.Errorf("creating snapshot ref: %v: %s", err, out)
+	}
+
+	// Apply the retention policy; a failure here does not affect the new snapshot
+	if cfg, err := state.LoadConfig(); err == nil {
+		if snapshots, err := listSnapshots(dir, entry.BranchName); err == nil {
+			pruneSnapshots(dir, snapshots, cfg.ForRepo(entry.GitRepo))
+		}
+	}
+
+	return name, nil
+}
+
+// listSnapshots returns the snapshots of the repository containing dir, newest
+// first, optionally only those of one branch
+func listSnapshots(dir, branch string) ([]snapshot, error) {
+	pattern := strings.TrimSuffix(snapshotRefPrefix, "/")
+	if branch != "" {
+		pattern = snapshotRefPrefix + branch
+	}
+	output, err := gitOutput(dir, "for-each-ref", "--sort=-creatordate", "--format=%(refname)%09%(objectname:short)%09%(creatordate:unix)%09%(subject)", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []snapshot
+	for _, line := range splitLines(output) {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		s := snapshot{ref: fields[0], commit: fields[1], subject: fields[3]}
+		s.name = strings.TrimPrefix(s.ref, snapshotRefPrefix)
+		s.branch = s.name[:strings.LastIndex(s.name, "/")]
+		if branch != "" && s.branch != branch {
+			continue // A branch whose name starts with branch + "/"
+		}
+		if seconds, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			s.created = time.Unix(seconds, 0)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+// findSnapshot resolves a snapshot name (<branch>/<timestamp>), a full ref or a
+// branch name, which means the branch's latest snapshot
+func findSnapshot(name string) (snapshot, error) {
+	snapshots, err := listSnapshots("", "")
+	if err != nil {
+		return snapshot{}, err
+	}
+	for _, s := range snapshots {
+		if s.name == name || s.ref == name {
+			return s, nil
+		}
+	}
+	branch := name
+	if !containsSnapshotBranch(snapshots, name) {
+		if branch, err = resolveBranchName(name); err != nil {
+			return snapshot{}, err
+		}
+	}
+	for _, s := range snapshots {
+		if s.branch == branch {
+			return s, nil
+		}
+	}
+	return snapshot{}, fmt.Errorf("no snapshot matches '%s'", name)
+}
+
+// containsSnapshotBranch reports whether any of snapshots was taken on branch
+func containsSnapshotBranch(snapshots []snapshot, branch string) bool {
+	for _, s := range snapshots {
+		if s.branch == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneSnapshots deletes the snapshots that fall outside the retention policy
+// of rc. snapshots must be sorted newest first. Returns the number deleted.
+func pruneSnapshots(dir string, snapshots []snapshot, rc state.RepoConfig) (int, error) {
+	keep := rc.SnapshotKeep
+	if keep == 0 {
+		keep = defaultSnapshotKeep
+	}
+	var ttl time.Duration
+	if rc.SnapshotTTL != "" {
+		var err error
+		if ttl, err = parseAge(rc.SnapshotTTL); err != nil {
+			return 0, fmt.Errorf("invalid snapshot_ttl: %w", err)
+		}
+	}
+
+	deleted := 0
+	seen := make(map[string]int) // Snapshots kept so far per branch
+	for _, s := range snapshots {
+		expired := ttl > 0 && time.Since(s.created) > ttl
+		if !expired && seen[s.branch] < keep {
+			seen[s.branch]++
+			continue
+		}
+		if _, err := gitChange(dir, "update-ref", "-d", s.ref); err != nil {
+			return deleted, fmt.Errorf("deleting %s: %w", s.name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,56 +14,124 @@ import (
 )
 
 var silent bool
+var switchRoot bool
 
 var switchCmd = &cobra.Command{
-	Use:     "switch [branch-name]",
-	Short:   "Switch to an existing worktree",
+	Use:   "switch [branch-name] [-- command [args...]]",
+	Short: "Switch to an existing worktree",
+	Long: `Switch to the worktree of a branch. Without a branch name, a fuzzy finder
+over the managed worktrees is opened.
+
+'-' returns to the worktree left by the last switch, within the current
+repository or, outside one, across all repositories. '@main' and '.' switch to
+the primary checkout of the current repository.
+
+Within a repository, the same subdirectory of the target worktree is entered
+as the one the current directory is in, or its nearest existing parent. Use
+--root to enter the worktree root instead.
+
+A command after -- is run at the worktree root instead of a shell, like with
+run. The exit status of the shell or command is passed on.`,
 	Aliases: []string{"s"},
-	Args:    cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if n := cmd.ArgsLenAtDash(); n > 1 || (n < 0 && len(args) > 1) {
+			return fmt.Errorf("accepts at most 1 branch name before --")
+		}
+		return nil
+	},
+	ValidArgsFunction: completeSwitchTarget,
 	Run: func(cmd *cobra.Command, args []string) {
-		branchName := args[0]
-
-		// Get the remote URL
-		remoteURLBytes, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
-		if err != nil {
-			fmt.Printf("Error getting remote origin URL: %v\n", err)
-			return
+		var command []string
+		if n := cmd.ArgsLenAtDash(); n >= 0 {
+			args, command = args[:n], args[n:]
 		}
-		remoteURL := strings.TrimSpace(string(remoteURLBytes))
-		// Parse organization/username and repo name from remote URL
-		orgRepo := ParseRemoteURL(remoteURL)
-		if orgRepo == "" {
-			fmt.Printf("Could not parse organization/username and repository name from remote URL: %s\n", remoteURL)
-			return
+		if code := switchTo(cmd, args, command); code != 0 {
+			os.Exit(code)
 		}
+	},
+}
 
-		// Initialize state manager
-		stateManager, err := state.NewStateManager()
-		if err != nil {
-			fmt.Printf("Error initializing state manager: %v\n", err)
-			return
+// switchTo enters the worktree named by args, or picked in the fuzzy finder
+// without args, and runs command there, a shell if it is empty. Returns the
+// exit status of the shell or command, or 1 if the worktree was not entered.
+func switchTo(cmd *cobra.Command, args []string, command []string) int {
+	if len(args) == 0 {
+		entry, err := pickWorktree("switch")
+		if errors.Is(err, errPickerCancelled) {
+			return 0
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
 		}
-
-		// Try to get worktree from state first
-		entry, exists := stateManager.GetWorktree(orgRepo, branchName)
-		if exists {
-			SwitchToWorktreeByPath(entry.Path, silent)
-			return
+		if stateManager, err := state.NewStateManager(); err == nil {
+			stateManager.GetWorktree(entry.GitRepo, entry.BranchName) // Record the access
 		}
+		return SwitchToWorktreeByPath(entry.Path, silent, command...)
+	}
+	branchName := args[0]
+	if dir, ok, err := switchShortcut(branchName); ok {
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		return SwitchToWorktreeByPath(dir, silent, command...)
+	}
 
-		// Fallback to old behavior if not found in state
-		// Determine the common worktree directory (same logic as create command)
-		defaultWorktreeDir := GetDefaultWorktreeDir()
-		if envVar := os.Getenv("GIT_WORKTREE_MANAGER_DIR"); envVar != "" {
-			defaultWorktreeDir = envVar
+	// Get the remote URL
+	remoteURLBytes, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		// Outside a repository, look through the worktrees of every repository
+		stateManager, smErr := state.NewStateManager()
+		if smErr != nil {
+			fmt.Printf("Error initializing state manager: %v\n", smErr)
+			return 1
 		}
-		// If the flag was set, it overrides everything
-		if cmd.Flags().Changed("worktree-dir") {
-			defaultWorktreeDir = commonWorktreeDir // commonWorktreeDir is populated by the flag
+		entry, resolveErr := resolveWorktree(stateManager, "", branchName)
+		if resolveErr == nil {
+			return SwitchToWorktreeByPath(entry.Path, silent, command...)
+		} else if !errors.Is(resolveErr, errNotRegistered) {
+			fmt.Printf("Error: %v\n", resolveErr)
+			return 1
 		}
+		fmt.Printf("Error getting remote origin URL: %v\n", err)
+		return 1
+	}
+	remoteURL := strings.TrimSpace(string(remoteURLBytes))
+	// Parse organization/username and repo name from remote URL
+	orgRepo := ParseRemoteURL(remoteURL)
+	if orgRepo == "" {
+		fmt.Printf("Could not parse organization/username and repository name from remote URL: %s\n", remoteURL)
+		return 1
+	}
 
-		SwitchToWorktree(branchName, orgRepo, defaultWorktreeDir, silent)
-	},
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		fmt.Printf("Error initializing state manager: %v\n", err)
+		return 1
+	}
+
+	// Try to get worktree from state first
+	entry, err := resolveWorktree(stateManager, orgRepo, branchName)
+	if err == nil {
+		return SwitchToWorktreeByPath(entry.Path, silent, command...)
+	} else if !errors.Is(err, errNotRegistered) {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	// Fallback to old behavior if not found in state
+	// Determine the common worktree directory (same logic as create command)
+	defaultWorktreeDir := GetDefaultWorktreeDir()
+	if envVar := os.Getenv("GIT_WORKTREE_MANAGER_DIR"); envVar != "" {
+		defaultWorktreeDir = envVar
+	}
+	// If the flag was set, it overrides everything
+	if cmd.Flags().Changed("worktree-dir") {
+		defaultWorktreeDir = commonWorktreeDir // commonWorktreeDir is populated by the flag
+	}
+
+	return SwitchToWorktree(branchName, orgRepo, defaultWorktreeDir, silent, command...)
 }
 
 func init() {
@@ -73,65 +142,89 @@ func init() {
 	}
 	switchCmd.Flags().StringVarP(&commonWorktreeDir, "worktree-dir", "w", defaultWorktreeDir, "Base directory for new worktrees")
 	switchCmd.Flags().BoolVarP(&silent, "silent", "s", false, "Suppress output messages")
+	switchCmd.Flags().BoolVarP(&switchRoot, "root", "r", false, "Enter the worktree root instead of the current subdirectory")
 }
 
-func SwitchToWorktreeByPath(worktreePath string, silent bool) {
+// SwitchToWorktreeByPath opens a shell in the worktree at worktreePath, or runs
+// command there if given, and returns its exit status
+func SwitchToWorktreeByPath(worktreePath string, silent bool, command ...string) int {
+	// In dry-run mode there is nothing to enter; the worktree may not even exist yet
+	if planRun(describeCommand(command), worktreePath) {
+		return 0
+	}
+
 	// Check if the worktree directory exists
 	_, err := os.Stat(worktreePath)
 	if os.IsNotExist(err) {
 		fmt.Printf("Worktree not found at '%s'\n", worktreePath)
-		return
+		return 1
 	} else if err != nil {
 		fmt.Printf("Error checking worktree path '%s': %v\n", worktreePath, err)
-		return
-	}
-
-	if !silent {
-		fmt.Printf("Switching to worktree at '%s'\n", worktreePath)
-	}
-
-	// Determine the user's shell
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		// Fallback for Windows or if SHELL is not set
-		if runtime.GOOS == "windows" {
-			shell = "cmd.exe"
-		} else {
-			shell = "bash"
-		}
+		return 1
 	}
 
-	// Execute a new shell in the worktree directory
-	cmdShell := exec.Command(shell)
-	cmdShell.Dir = worktreePath
-	cmdShell.Stdin = os.Stdin
-	cmdShell.Stdout = os.Stdout
-	cmdShell.Stderr = os.Stderr
-
-	if err := cmdShell.Run(); err != nil {
-		fmt.Printf("Error starting shell in worktree: %v\n", err)
-		return
-	}
+	return enterWorktree(worktreePath, silent, command)
 }
 
-func SwitchToWorktree(branchName string, orgRepo string, worktreeDir string, silent bool) {
+// SwitchToWorktree opens a shell in the worktree of branchName below
+// worktreeDir, or runs command there if given, and returns its exit status
+func SwitchToWorktree(branchName string, orgRepo string, worktreeDir string, silent bool, command ...string) int {
 	worktreePath := filepath.Join(worktreeDir, orgRepo, branchName)
+	if planRun(describeCommand(command), worktreePath) {
+		return 0
+	}
 
 	// Check if the worktree directory exists
 	_, err := os.Stat(worktreePath)
 	if os.IsNotExist(err) {
 		fmt.Printf("Worktree for branch '%s' not found at '%s'\n", branchName, worktreePath)
-		return
+		return 1
 	} else if err != nil {
 		fmt.Printf("Error checking worktree path '%s': %v\n", worktreePath, err)
-		return
+		return 1
 	}
 
-	if !silent {
-		fmt.Printf("Switching to worktree at '%s'\n", worktreePath)
+	return enterWorktree(worktreePath, silent, command)
+}
+
+// enterWorktree opens a shell in the worktree at worktreePath, or runs command
+// there if given, and returns its exit status. Under the shell-init wrapper
+// the calling shell changes into the worktree instead of a new shell starting.
+func enterWorktree(worktreePath string, silent bool, command []string) int {
+	dir := worktreePath
+	reason := fmt.Sprintf("in use by '%s' run by git-worktree-manager (pid %d)", describeCommand(command), os.Getpid())
+	if len(command) == 0 {
+		dir = landingDir(worktreePath)
+		recordSwitch(worktreePath)
+		if !silent {
+			fmt.Printf("Switching to worktree at '%s'\n", dir)
+		}
+
+		// Under the shell-init wrapper the calling shell changes directory itself
+		if changeDirectory(dir) {
+			return 0
+		}
+
+		if depth := nestingDepth(); depth > 0 {
+			fmt.Printf("Warning: already in a shell started by git-worktree-manager; this one is nested %d deep, exit it to get back\n", depth+1)
+		}
+		command = []string{userShell()}
+		reason = fmt.Sprintf("in use by a git-worktree-manager shell (pid %d)", os.Getpid())
 	}
 
-	// Determine the user's shell
+	// Keep the worktree from being pruned or removed while the shell is open
+	unlock := lockWorktree(worktreePath, reason)
+	defer unlock()
+
+	code, err := runForeground(dir, worktreeEnvAt(worktreePath), command)
+	if err != nil {
+		fmt.Printf("Error running %s in worktree: %v\n", command[0], err)
+	}
+	return code
+}
+
+// userShell returns the user's shell
+func userShell() string {
 	shell := os.Getenv("SHELL")
 	if shell == "" {
 		// Fallback for Windows or if SHELL is not set
@@ -141,16 +234,83 @@ func SwitchToWorktree(branchName string, orgRepo string, worktreeDir string, sil
 			shell = "bash"
 		}
 	}
+	return shell
+}
+
+// describeCommand returns command as shown in a dry-run plan; $SHELL for none
+func describeCommand(command []string) string {
+	if len(command) == 0 {
+		return "$SHELL"
+	}
+	return strings.Join(command, " ")
+}
 
-	// Execute a new shell in the worktree directory
-	cmdShell := exec.Command(shell)
-	cmdShell.Dir = worktreePath
-	cmdShell.Stdin = os.Stdin
-	cmdShell.Stdout = os.Stdout
-	cmdShell.Stderr = os.Stderr
+// switchShortcut resolves the switch targets that are not branch names: '-'
+// for the worktree left by the last switch, '@main' and '.' for the primary
+// checkout of the current repository. ok is false for any other name.
+func switchShortcut(name string) (dir string, ok bool, err error) {
+	switch name {
+	case "-":
+		repo, _ := currentRepoName() // Outside a repository, the last switch anywhere
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			return "", true, fmt.Errorf("initializing state manager: %w", err)
+		}
+		dir, found := stateManager.Previous(repo)
+		if !found {
+			return "", true, fmt.Errorf("no previous worktree to return to")
+		}
+		return dir, true, nil
+	case "@main", ".":
+		dir, err := mainCheckoutPath("")
+		if err != nil {
+			return "", true, fmt.Errorf("not inside a repository")
+		}
+		return dir, true, nil
+	}
+	return "", false, nil
+}
 
-	if err := cmdShell.Run(); err != nil {
-		fmt.Printf("Error starting shell in worktree: %v\n", err)
+// recordSwitch remembers the worktree containing the current directory as the
+// one to return to with 'switch -', unless it is the target itself
+func recordSwitch(target string) {
+	current, err := gitOutput("", "rev-parse", "--show-toplevel")
+	if err != nil || samePath(current, target) {
 		return
 	}
+	repo, _ := currentRepoName()
+	if stateManager, err := state.NewStateManager(); err == nil {
+		stateManager.SetPrevious(repo, current)
+	}
+}
+
+// landingDir returns the directory to enter in the worktree at worktreePath:
+// the subdirectory the current directory is in within the current worktree,
+// or its nearest existing parent. Outside the repository of the target, or
+// with --root, it is the worktree root.
+func landingDir(worktreePath string) string {
+	if switchRoot {
+		return worktreePath
+	}
+	prefix, err := gitOutput("", "rev-parse", "--show-prefix")
+	if err != nil || prefix == "" {
+		return worktreePath
+	}
+	current, err := mainCheckoutPath("")
+	if err != nil {
+		return worktreePath
+	}
+	if target, err := mainCheckoutPath(worktreePath); err != nil || !samePath(current, target) {
+		return worktreePath
+	}
+
+	root := filepath.Clean(worktreePath)
+	dir := filepath.Join(root, filepath.FromSlash(prefix))
+	for dir != root && isWithinDir(dir, root) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		dir = filepath.Dir(dir)
+	}
+	return worktreePath
 }
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var syncRebase bool
+var syncRepo string
+
+// syncResult is the outcome of syncing a single worktree
+type syncResult struct {
+	entry   state.WorktreeEntry
+	updated bool
+	skipped bool
+	failed  bool
+	message string
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fetch every repository once and fast-forward clean worktrees",
+	Long: `Fetch each repository with managed worktrees once, then bring every clean
+worktree up to date with its upstream branch.
+
+Worktrees with uncommitted changes are skipped. Worktrees whose branch has
+diverged from its upstream are skipped unless --rebase is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		entries := stateManager.ListWorktrees()
+		if syncRepo != "" {
+			entries = stateManager.ListWorktreesByRepo(syncRepo)
+		}
+		groups := groupByRepo(entries)
+		if len(groups) == 0 {
+			fmt.Println("No managed worktrees to sync")
+			return
+		}
+
+		// Repositories are independent, so they are synced concurrently and reported in order
+		results := make([][]syncResult, len(groups))
+		fetchErrors := make([]error, len(groups))
+		var wg sync.WaitGroup
+		for i, group := range groups {
+			wg.Add(1)
+			go func(i int, group *repoGroup) {
+				defer wg.Done()
+				results[i], fetchErrors[i] = syncRepoGroup(group, syncRebase)
+			}(i, group)
+		}
+		wg.Wait()
+
+		var updated, skipped, failed int
+		for i, group := range groups {
+			fmt.Printf("%s\n", group.repo)
+			if fetchErrors[i] != nil {
+				fmt.Printf("  ✗ fetch failed: %v\n", fetchErrors[i])
+				failed += len(group.rows)
+				continue
+			}
+			for _, result := range results[i] {
+				symbol := "✓"
+				switch {
+				case result.failed:
+					symbol = "✗"
+					failed++
+				case result.skipped:
+					symbol = "-"
+					skipped++
+				case result.updated:
+					updated++
+				}
+				fmt.Printf("  %s %s: %s\n", symbol, result.entry.BranchName, result.message)
+			}
+		}
+
+		fmt.Printf("\nUpdated %d, skipped %d, failed %d\n", updated, skipped, failed)
+	},
+}
+
+func init() {
+	syncCmd.Flags().BoolVarP(&syncRebase, "rebase", "r", false, "Rebase diverged branches onto their upstream instead of skipping them")
+	syncCmd.Flags().StringVar(&syncRepo, "repo", "", "Only sync worktrees of this repository (owner/repo)")
+	syncCmd.RegisterFlagCompletionFunc("repo", completeRepos)
+	rootCmd.AddCommand(syncCmd)
+}
+
+// syncRepoGroup fetches the repository once and then updates each of its worktrees
+func syncRepoGroup(group *repoGroup, rebase bool) ([]syncResult, error) {
+	fetchDir, err := group.checkoutDir()
+	if err != nil {
+		return nil, err
+	}
+	if out, err := gitChange(fetchDir, "fetch", "--all", "--prune"); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+
+	sortListRows(group.rows, "branch")
+	results := make([]syncResult, 0, len(group.rows))
+	for _, row := range group.rows {
+		results = append(results, syncWorktree(row.entry, rebase))
+	}
+	return results, nil
+}
+
+// syncWorktree brings a single worktree up to date with its upstream
+func syncWorktree(entry state.WorktreeEntry, rebase bool) syncResult {
+	result := syncResult{entry: entry}
+	skip := func(format string, a ...interface{}) syncResult {
+		result.skipped = true
+		result.message = "skipped, " + fmt.Sprintf(format, a...)
+		return result
+	}
+	fail := func(format string, a ...interface{}) syncResult {
+		result.failed = true
+		result.message = fmt.Sprintf(format, a...)
+		return result
+	}
+
+	if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+		return skip("worktree not found at '%s'", entry.Path)
+	}
+
+	dirty, err := isWorktreeDirty(entry.Path)
+	if err != nil {
+		return fail("error checking status: %v", err)
+	}
+	if dirty {
+		return skip("uncommitted changes")
+	}
+
+	upstream, err := gitOutput(entry.Path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return skip("no upstream branch")
+	}
+
+	ahead, behind, err := aheadBehind(entry.Path, "HEAD", upstream)
+	if err != nil {
+		return fail("error comparing with %s: %v", upstream, err)
+	}
+
+	switch {
+	case behind == 0 && ahead == 0:
+		result.message = "up to date"
+	case behind == 0:
+		result.message = fmt.Sprintf("ahead of %s by %d commit(s)", upstream, ahead)
+	case ahead == 0:
+		if out, err := gitChange(entry.Path, "merge", "--ff-only", upstream); err != nil {
+			return fail("fast-forward failed: %v: %s", err, out)
+		}
+		result.updated = true
+		result.message = fmt.Sprintf("fast-forwarded %d commit(s) from %s", behind, upstream)
+	case rebase:
+		if out, err := gitChange(entry.Path, "rebase", upstream); err != nil {
+			gitChange(entry.Path, "rebase", "--abort")
+			return fail("rebase onto %s failed and was aborted: %s", upstream, out)
+		}
+		result.updated = true
+		result.message = fmt.Sprintf("rebased %d commit(s) onto %s (%d new upstream commit(s))", ahead, upstream, behind)
+	default:
+		return skip("diverged from %s (ahead %d, behind %d)", upstream, ahead, behind)
+	}
+
+	return result
+}
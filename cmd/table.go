@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// table renders rows as aligned columns
+type table struct {
+	headers []string
+	rows    [][]string
+	// flex is the index of the column that is truncated first when the table
+	// is wider than the terminal, or -1 to shrink the widest column instead.
+	// The flex column keeps its tail, which suits paths.
+	flex int
+}
+
+func newTable(headers ...string) *table {
+	return &table{headers: headers, flex: -1}
+}
+
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// render writes the table to w. Each row may be preceded by a marker (such as
+// the active worktree indicator), passed through markers in row order.
+// When maxWidth is positive, columns are truncated so each line fits.
+func (t *table) render(w io.Writer, markers []string, maxWidth int) {
+	const gap = 2
+	markerWidth := 0
+	for _, m := range markers {
+		if n := utf8.RuneCountInString(m); n > markerWidth {
+			markerWidth = n
+		}
+	}
+
+	widths := make([]int, len(t.headers))
+	for i, h := range t.headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	if maxWidth > 0 {
+		t.fit(widths, maxWidth-markerWidth-gap*(len(widths)-1))
+	}
+
+	line := func(marker string, cells []string) {
+		var b strings.Builder
+		b.WriteString(pad(marker, markerWidth))
+		for i, cell := range cells {
+			if i == t.flex {
+				cell = truncateLeft(cell, widths[i])
+			} else {
+				cell = truncate(cell, widths[i])
+			}
+			if i == len(cells)-1 {
+				b.WriteString(cell)
+			} else {
+				b.WriteString(pad(cell, widths[i]+gap))
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+
+	line("", t.headers)
+	for i, row := range t.rows {
+		marker := ""
+		if i < len(markers) {
+			marker = markers[i]
+		}
+		line(marker, row)
+	}
+}
+
+// fit shrinks widths until their sum is no larger than avail
+func (t *table) fit(widths []int, avail int) {
+	const minWidth = 6
+	for {
+		total := 0
+		for _, w := range widths {
+			total += w
+		}
+		excess := total - avail
+		if excess <= 0 {
+			return
+		}
+
+		col := t.flex
+		if col < 0 || widths[col] <= minWidth {
+			col = -1
+			for i, w := range widths {
+				if w > minWidth && (col < 0 || w > widths[col]) {
+					col = i
+				}
+			}
+		}
+		if col < 0 {
+			return // Nothing left to shrink
+		}
+
+		shrink := widths[col] - minWidth
+		if shrink > excess {
+			shrink = excess
+		}
+		widths[col] -= shrink
+	}
+}
+
+// pad right-pads s with spaces to width runes
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// truncateLeft shortens s to width runes by dropping its beginning
+func truncateLeft(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+// terminalWidth returns the width of the terminal attached to stdout,
+// falling back to $COLUMNS, or 0 when output is not a terminal.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
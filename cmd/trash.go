@@ -0,0 +1,544 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// trashRefPrefix holds a ref to the tip of every trashed worktree, so its
+// commits survive garbage collection even when the branch was deleted
+const trashRefPrefix = "refs/worktree-manager/trash/"
+
+var trashOlderThan string
+var trashYes bool
+
+// trashItem describes a worktree in the trash. It is stored as meta.json next
+// to the worktree's files.
+type trashItem struct {
+	Entry         state.WorktreeEntry `json:"entry"`
+	Head          string              `json:"head"`                    // Commit the worktree was at
+	Detached      bool                `json:"detached"`                // No branch was checked out
+	BranchDeleted bool                `json:"branch_deleted"`          // The branch was deleted along with the worktree
+	BranchConfig  []configValue       `json:"branch_config,omitempty"` // branch.<name>.* settings dropped with the branch
+	GitDir        string              `json:"git_dir"`                 // Common git directory holding the trash ref
+	TrashedAt     time.Time           `json:"trashed_at"`
+
+	id  string // Name of the item's directory in the trash
+	dir string
+}
+
+// configValue is a single git config setting
+type configValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage worktrees removed with 'remove --trash'",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List worktrees in the trash",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		items, err := listTrash(stateManager)
+		if err != nil {
+			fmt.Printf("Error reading trash: %v\n", err)
+			return
+		}
+		if len(items) == 0 {
+			fmt.Println("Trash is empty")
+			return
+		}
+
+		var total int64
+		t := newTable("ID", "REPO", "BRANCH", "AGE", "SIZE")
+		for _, item := range items {
+			size := dirSize(item.dir)
+			total += size
+			t.addRow(item.id, item.Entry.GitRepo, item.Entry.BranchName, formatAge(time.Since(item.TrashedAt)), formatBytes(size))
+		}
+		t.render(os.Stdout, nil, terminalWidth())
+		fmt.Printf("\n%d item(s), %s\n", len(items), formatBytes(total))
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete worktrees from the trash",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var olderThan time.Duration
+		if trashOlderThan != "" {
+			var err error
+			olderThan, err = parseAge(trashOlderThan)
+			if err != nil {
+				fmt.Printf("Error parsing --older-than: %v\n", err)
+				return
+			}
+		}
+
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		items, err := listTrash(stateManager)
+		if err != nil {
+			fmt.Printf("Error reading trash: %v\n", err)
+			return
+		}
+
+		var expired []trashItem
+		for _, item := range items {
+			if time.Since(item.TrashedAt) >= olderThan {
+				expired = append(expired, item)
+			}
+		}
+		if len(expired) == 0 {
+			fmt.Println("Nothing to purge")
+			return
+		}
+		if !trashYes && !confirm(fmt.Sprintf("Permanently delete %d item(s) from the trash?", len(expired))) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		purged := 0
+		var reclaimed int64
+		for _, item := range expired {
+			size := dirSize(item.dir)
+			if err := purgeTrashItem(item); err != nil {
+				fmt.Printf("Error purging '%s': %v\n", item.id, err)
+				continue
+			}
+			purged++
+			reclaimed += size
+		}
+		fmt.Printf("Purged %d item(s), reclaimed %s\n", purged, formatBytes(reclaimed))
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [id|branch-name]",
+	Short: "Restore a worktree from the trash",
+	Long: `Move a worktree from the trash back to its original location and register it
+again. The branch is recreated at its old tip if it was deleted. A branch name
+restores the most recently trashed worktree of that branch.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTrashItem,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Initialize state manager
+		stateManager, err := state.NewStateManager()
+		if err != nil {
+			fmt.Printf("Error initializing state manager: %v\n", err)
+			return
+		}
+
+		items, err := listTrash(stateManager)
+		if err != nil {
+			fmt.Printf("Error reading trash: %v\n", err)
+			return
+		}
+		// Items are sorted newest first, so the first match by worktree is the latest
+		var item *trashItem
+		var entries []state.WorktreeEntry
+		for i := range items {
+			if items[i].id == args[0] {
+				item = &items[i]
+				break
+			}
+			if !containsEntry(entries, items[i].Entry.ID) {
+				entries = append(entries, items[i].Entry)
+			}
+		}
+		if item == nil {
+			entry, err := matchWorktree(args[0], entries, true)
+			if errors.Is(err, errNotRegistered) {
+				fmt.Printf("No trashed worktree matches '%s'\n", args[0])
+				return
+			} else if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			for i := range items {
+				if items[i].Entry.ID == entry.ID {
+					item = &items[i]
+					break
+				}
+			}
+		}
+
+		if err := restoreTrashItem(stateManager, *item); err != nil {
+			fmt.Printf("Error restoring '%s': %v\n", item.id, err)
+			return
+		}
+		printDone("Restored worktree for branch '%s' at '%s'\n", item.Entry.BranchName, item.Entry.Path)
+	},
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only purge items trashed longer ago than this (e.g. 30d)")
+	trashEmptyCmd.Flags().BoolVarP(&trashYes, "yes", "y", false, "Do not ask for confirmation")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// trashDir returns the directory that holds trashed worktrees, next to the state file
+func trashDir(stateManager *state.StateManager) string {
+	return filepath.Join(filepath.Dir(stateManager.GetConfigPath()), "trash")
+}
+
+// listTrash returns the items in the trash, newest first
+func listTrash(stateManager *state.StateManager) ([]trashItem, error) {
+	dirs, err := os.ReadDir(trashDir(stateManager))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var items []trashItem
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(trashDir(stateManager), d.Name())
+		data, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+		if err != nil {
+			continue // Not a trash item, or one that is still being written
+		}
+		var item trashItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			continue
+		}
+		item.id = d.Name()
+		item.dir = dir
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].TrashedAt.After(items[j].TrashedAt) })
+	return items, nil
+}
+
+// trashWorktree moves the worktree of entry into the trash instead of deleting
+// it. Git's metadata for the worktree is dropped, a ref keeps its commits alive
+// and the entry is unregistered. Returns the ID of the new trash item.
+func trashWorktree(stateManager *state.StateManager, entry state.WorktreeEntry, gitRoot string, deleteBranch bool) (string, error) {
+	if _, err := os.Stat(entry.Path); err != nil {
+		return "", fmt.Errorf("worktree for branch '%s' not found at '%s'", entry.BranchName, entry.Path)
+	}
+	if deleteBranch {
+		if err := checkBranchDeletion(entry, gitRoot); err != nil {
+			return "", err
+		}
+	}
+	head, err := gitOutput(entry.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("reading HEAD: %w", err)
+	}
+	_, err = gitOutput(entry.Path, "symbolic-ref", "--quiet", "HEAD")
+	item := trashItem{
+		Entry:     entry,
+		Head:      head,
+		Detached:  err != nil,
+		TrashedAt: time.Now(),
+	}
+	adminDir, _ := readGitLink(entry.Path)
+	if item.GitDir, err = gitOutput(gitRoot, "rev-parse", "--path-format=absolute", "--git-common-dir"); err != nil {
+		return "", fmt.Errorf("finding the repository: %w", err)
+	}
+
+	// The random suffix keeps items of the same branch apart, and of branches
+	// that only differ in '/' and '-'
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	item.id = strings.Join([]string{
+		item.TrashedAt.Format("20060102-150405"),
+		strings.ReplaceAll(entry.GitRepo, "/", "-"),
+		strings.ReplaceAll(entry.BranchName, "/", "-"),
+		hex.EncodeToString(suffix),
+	}, "-")
+	item.dir = filepath.Join(trashDir(stateManager), item.id)
+	item.BranchDeleted = deleteBranch && !item.Detached
+	if item.BranchDeleted {
+		// Settings such as the upstream go with the branch and come back with it
+		if item.BranchConfig, err = branchConfig(gitRoot, entry.BranchName); err != nil {
+			return "", fmt.Errorf("reading branch configuration: %w", err)
+		}
+	}
+
+	// Every step up to unregistering the worktree is undone if a later one
+	// fails, so the worktree is either fully in the trash or untouched
+	var undo []func()
+	fail := func(err error) (string, error) {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		return "", err
+	}
+
+	// Never reuse a directory or ref: undoing would destroy another item
+	if err := fileChange("create", item.dir, func() error {
+		if err := os.MkdirAll(trashDir(stateManager), 0755); err != nil {
+			return err
+		}
+		return os.Mkdir(item.dir, 0755)
+	}); err != nil {
+		return "", err
+	}
+	undo = append(undo, func() { os.RemoveAll(item.dir) })
+
+	trashRef := trashRefPrefix + item.id
+	if out, err := gitChange(gitRoot, "update-ref", trashRef, head, ""); err != nil {
+		return fail(fmt.Errorf("recording branch tip: %v: %s", err, out))
+	}
+	undo = append(undo, func() { gitOutput(gitRoot, "update-ref", "-d", trashRef) })
+
+	// Keep the index so staged changes survive
+	if adminDir != "" {
+		index := filepath.Join(item.dir, "index")
+		fileChange("copy", filepath.Join(adminDir, "index")+" -> "+index, func() error {
+			return copyFile(filepath.Join(adminDir, "index"), index)
+		})
+	}
+
+	trashed := filepath.Join(item.dir, "worktree")
+	if err := fileChange("move", entry.Path+" -> "+trashed, func() error { return os.Rename(entry.Path, trashed) }); err != nil {
+		if errors.Is(err, syscall.EXDEV) {
+			err = fmt.Errorf("the trash (%s) is on a different filesystem than the worktree; remove it without --trash instead", trashDir(stateManager))
+		}
+		return fail(fmt.Errorf("moving worktree to the trash: %w", err))
+	}
+	undo = append(undo, func() { os.Rename(trashed, entry.Path) })
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fail(err)
+	}
+	meta := filepath.Join(item.dir, "meta.json")
+	if err := fileChange("write", meta, func() error { return os.WriteFile(meta, data, 0644) }); err != nil {
+		return fail(err)
+	}
+
+	if item.BranchDeleted {
+		// Git's metadata for the worktree still exists, so delete the ref
+		// directly; git branch -D refuses branches checked out in a worktree
+		branchRef := "refs/heads/" + entry.BranchName
+		if out, err := gitChange(gitRoot, "update-ref", "-d", branchRef, head); err != nil {
+			return fail(fmt.Errorf("removing branch '%s': %v: %s", entry.BranchName, err, out))
+		}
+		undo = append(undo, func() { gitOutput(gitRoot, "update-ref", branchRef, head) })
+	}
+
+	if err := stateManager.RemoveWorktree(entry.GitRepo, entry.BranchName); err != nil {
+		return fail(fmt.Errorf("removing worktree from state: %w", err))
+	}
+
+	// Drop what git branch -D would have and detach git's metadata; the trash
+	// item is complete at this point
+	if item.BranchDeleted {
+		gitChange(gitRoot, "config", "--remove-section", "branch."+entry.BranchName)
+	}
+	if adminDir != "" {
+		fileChange("delete", adminDir, func() error { return os.RemoveAll(adminDir) })
+	}
+	fileChange("delete", filepath.Join(trashed, ".git"), func() error { return os.Remove(filepath.Join(trashed, ".git")) })
+	return item.id, nil
+}
+
+// restoreTrashItem recreates the worktree of item at its original path, moves
+// its files back and registers it again
+func restoreTrashItem(stateManager *state.StateManager, item trashItem) error {
+	entry := item.Entry
+	if _, err := os.Stat(entry.Path); err == nil {
+		return fmt.Errorf("'%s' already exists", entry.Path)
+	}
+	for _, existing := range stateManager.ListWorktrees() {
+		if existing.ID == entry.ID {
+			return fmt.Errorf("'%s' is registered again at '%s'", entry.ID, existing.Path)
+		}
+	}
+
+	gitRoot := entry.RepoPath
+	if _, err := os.Stat(gitRoot); gitRoot == "" || err != nil {
+		gitRoot = strings.TrimSuffix(item.GitDir, string(filepath.Separator)+".git")
+		if _, err := os.Stat(gitRoot); item.GitDir == "" || err != nil {
+			if repo, err := currentRepoName(); err != nil || repo != entry.GitRepo {
+				return fmt.Errorf("main checkout of %s not found; run restore from inside the repository", entry.GitRepo)
+			}
+			gitRoot = "."
+		}
+	}
+
+	// Every step is undone if a later one fails, so the item stays in the
+	// trash with all its files
+	var undo []func()
+	fail := func(err error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		return err
+	}
+
+	addArgs := []string{"worktree", "add", "--no-checkout"}
+	if item.Detached {
+		addArgs = append(addArgs, "--detach", entry.Path, item.Head)
+	} else {
+		branchRef := "refs/heads/" + entry.BranchName
+		if _, err := gitOutput(gitRoot, "rev-parse", "--verify", "--quiet", branchRef); err != nil {
+			if out, err := gitChange(gitRoot, "branch", entry.BranchName, item.Head); err != nil {
+				return fmt.Errorf("recreating branch '%s': %v: %s", entry.BranchName, err, out)
+			}
+			undo = append(undo, func() { gitOutput(gitRoot, "update-ref", "-d", branchRef) })
+			for _, c := range item.BranchConfig {
+				if out, err := gitChange(gitRoot, "config", "--add", c.Key, c.Value); err != nil {
+					return fail(fmt.Errorf("restoring %s: %v: %s", c.Key, err, out))
+				}
+			}
+			undo = append(undo, func() { gitOutput(gitRoot, "config", "--remove-section", "branch."+entry.BranchName) })
+		}
+		addArgs = append(addArgs, entry.Path, entry.BranchName)
+	}
+	planFile("create", entry.Path)
+	if out, err := gitChange(gitRoot, addArgs...); err != nil {
+		return fail(fmt.Errorf("creating worktree: %v: %s", err, out))
+	}
+	undo = append(undo, func() { gitOutput(gitRoot, "worktree", "remove", "--force", entry.Path) })
+
+	files, err := os.ReadDir(filepath.Join(item.dir, "worktree"))
+	if err != nil {
+		return fail(err)
+	}
+	for _, file := range files {
+		src, dst := filepath.Join(item.dir, "worktree", file.Name()), filepath.Join(entry.Path, file.Name())
+		if err := fileChange("move", src+" -> "+dst, func() error { return os.Rename(src, dst) }); err != nil {
+			return fail(fmt.Errorf("moving '%s' back: %w", file.Name(), err))
+		}
+		undo = append(undo, func() { os.Rename(dst, src) })
+	}
+
+	// Bring back the saved index, or rebuild one from HEAD
+	restored := false
+	if adminDir, ok := readGitLink(entry.Path); ok {
+		src, dst := filepath.Join(item.dir, "index"), filepath.Join(adminDir, "index")
+		restored = fileChange("copy", src+" -> "+dst, func() error { return copyFile(src, dst) }) == nil
+	}
+	if !restored {
+		if out, err := gitChange(entry.Path, "reset", "--quiet"); err != nil {
+			return fail(fmt.Errorf("rebuilding index: %v: %s", err, out))
+		}
+	}
+
+	if err := stateManager.AddWorktree(entry.Path, entry.GitRepo, entry.BranchName, entry.RemoteURL, entry.RepoPath); err != nil {
+		return fail(err)
+	}
+	undo = append(undo, func() { stateManager.RemoveWorktree(entry.GitRepo, entry.BranchName) })
+
+	// Nicknames given to another worktree in the meantime stay with it
+	var aliases []string
+	for _, alias := range entry.Aliases {
+		claimed := false
+		for _, other := range stateManager.ListWorktreesByRepo(entry.GitRepo) {
+			if other.ID != entry.ID && (other.BranchName == alias || other.HasAlias(alias)) {
+				claimed = true
+				fmt.Printf("Not restoring nickname '%s': it refers to the worktree for branch '%s'\n", alias, other.BranchName)
+				break
+			}
+		}
+		if !claimed {
+			aliases = append(aliases, alias)
+		}
+	}
+	if err := stateManager.UpdateWorktree(entry.GitRepo, entry.BranchName, func(e *state.WorktreeEntry) {
+		e.Labels = entry.Labels
+		e.Aliases = aliases
+		e.Pinned = entry.Pinned
+		e.CreatedAt = entry.CreatedAt
+	}); err != nil {
+		return fail(err)
+	}
+
+	return purgeTrashItem(item)
+}
+
+// purgeTrashItem deletes a trash item and the ref that kept its commits alive
+func purgeTrashItem(item trashItem) error {
+	// Items trashed before the git directory was recorded only know the checkout
+	dir := item.GitDir
+	if dir == "" {
+		dir = item.Entry.RepoPath
+	}
+	if dir != "" {
+		gitChange(dir, "update-ref", "-d", trashRefPrefix+item.id)
+	}
+	return fileChange("delete", item.dir, func() error { return os.RemoveAll(item.dir) })
+}
+
+// branchConfig returns the branch.<branch>.* settings of the repository
+func branchConfig(dir, branch string) ([]configValue, error) {
+	out, err := gitOutput(dir, "config", "--null", "--get-regexp", "^branch\\."+regexp.QuoteMeta(branch)+"\\.")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil, nil // No settings
+	} else if err != nil {
+		return nil, err
+	}
+	var values []configValue
+	for _, record := range strings.Split(out, "\x00") {
+		key, value, _ := strings.Cut(record, "\n")
+		if key != "" {
+			values = append(values, configValue{Key: key, Value: value})
+		}
+	}
+	return values, nil
+}
+
+// copyFile copies the regular file src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
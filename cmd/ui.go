@@ -0,0 +1,776 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garymjr/git-worktree-manager/pkg/state"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// uiRefreshInterval is how often the dashboard refreshes the status of every worktree
+const uiRefreshInterval = 10 * time.Second
+
+// uiSizeInterval is how often the size of a worktree is measured again
+const uiSizeInterval = time.Minute
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open a full-screen dashboard of all managed worktrees",
+	Long: `Open a full-screen dashboard listing the managed worktrees of all repositories
+with their status, commits ahead of and behind their upstream, lock and size,
+refreshed in the background.
+
+Keys:
+  up/down, k/j   move
+  enter, s       switch to the worktree
+  c              create a worktree in the repository of the selected one
+  d              remove the worktree
+  l              lock or unlock the worktree
+  p              prune finished and expired worktrees
+  e              open the worktree in $VISUAL or $EDITOR
+  x              run a command in the worktree
+  r              refresh now
+  q, esc         quit`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entry, err := runDashboard()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if entry != nil {
+			SwitchToWorktreeByPath(entry.Path, false)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
+
+// uiStatus is the live status of a worktree shown by the dashboard
+type uiStatus struct {
+	loaded   bool
+	missing  bool
+	dirty    bool
+	upstream bool // The branch has an upstream; ahead and behind are only valid then
+	ahead    int
+	behind   int
+	locked   bool
+	size     int64
+	sizedAt  time.Time
+}
+
+// uiPrompt is a question shown in the footer. Line prompts collect text until
+// Enter; the others take a single y/n answer.
+type uiPrompt struct {
+	question string
+	line     bool
+	value    []rune
+	answer   func(string)
+}
+
+// dashboard is the state of the ui command
+type dashboard struct {
+	stateManager *state.StateManager
+	in           int
+	out          *os.File
+	rawState     *term.State
+
+	mu         sync.Mutex
+	entries    []state.WorktreeEntry
+	status     map[string]*uiStatus // Keyed by worktree ID
+	refreshing bool
+	refreshed  time.Time
+
+	cursor   int
+	offset   int
+	message  string // Outcome of the last action
+	prompt   *uiPrompt
+	switchTo *state.WorktreeEntry
+	redraw   chan struct{}
+}
+
+// runDashboard shows the dashboard until the user quits. It returns the
+// worktree to switch to, if the user chose one.
+func runDashboard() (*state.WorktreeEntry, error) {
+	in, out := int(os.Stdin.Fd()), os.Stdout
+	if !term.IsTerminal(in) || !term.IsTerminal(int(out.Fd())) {
+		return nil, fmt.Errorf("the dashboard needs a terminal")
+	}
+
+	// Initialize state manager
+	stateManager, err := state.NewStateManager()
+	if err != nil {
+		return nil, fmt.Errorf("initializing state manager: %w", err)
+	}
+
+	d := &dashboard{
+		stateManager: stateManager,
+		in:           in,
+		out:          out,
+		status:       make(map[string]*uiStatus),
+		redraw:       make(chan struct{}, 1),
+	}
+	d.reload()
+
+	if d.rawState, err = term.MakeRaw(in); err != nil {
+		return nil, err
+	}
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l") // Alternate screen, hide the cursor
+	defer func() {
+		fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+		term.Restore(in, d.rawState)
+	}()
+
+	// Keys are only read when asked for, so that nothing is taken away from
+	// an editor or command the dashboard runs in the foreground
+	want := make(chan struct{})
+	keys := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for range want {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+	want <- struct{}{}
+
+	ticker := time.NewTicker(uiRefreshInterval)
+	defer ticker.Stop()
+	go d.refresh()
+
+	for {
+		d.draw()
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				return nil, nil
+			}
+			if quit := d.handle(k); quit {
+				return d.switchTo, nil
+			}
+			want <- struct{}{}
+		case <-ticker.C:
+			go d.refresh()
+		case <-d.redraw:
+		}
+	}
+}
+
+// reload reads the registered worktrees from state, sorted by repository and branch
+func (d *dashboard) reload() {
+	entries := d.stateManager.ListWorktrees()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].GitRepo != entries[j].GitRepo {
+			return entries[i].GitRepo < entries[j].GitRepo
+		}
+		return entries[i].BranchName < entries[j].BranchName
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = entries
+	if d.cursor >= len(entries) {
+		d.cursor = len(entries) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+}
+
+// refresh updates the status of every worktree in the background, a few at a time
+func (d *dashboard) refresh() {
+	d.mu.Lock()
+	if d.refreshing {
+		d.mu.Unlock()
+		return
+	}
+	d.refreshing = true
+	entries := append([]state.WorktreeEntry(nil), d.entries...)
+	d.mu.Unlock()
+
+	sem := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry state.WorktreeEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			d.refreshEntry(entry)
+		}(entry)
+	}
+	wg.Wait()
+
+	d.mu.Lock()
+	d.refreshing = false
+	d.refreshed = time.Now()
+	d.mu.Unlock()
+	d.requestRedraw()
+}
+
+// refreshEntry measures the status of a single worktree
+func (d *dashboard) refreshEntry(entry state.WorktreeEntry) {
+	d.mu.Lock()
+	previous := d.status[entry.ID]
+	d.mu.Unlock()
+
+	status := &uiStatus{loaded: true}
+	if _, err := os.Stat(entry.Path); err != nil {
+		status.missing = true
+	} else {
+		status.dirty, _ = isWorktreeDirty(entry.Path)
+		if ahead, behind, err := aheadBehind(entry.Path, "HEAD", "@{upstream}"); err == nil {
+			status.upstream, status.ahead, status.behind = true, ahead, behind
+		}
+		if worktrees, err := listGitWorktrees(entry.Path); err == nil {
+			for _, wt := range worktrees {
+				if samePath(wt.Path, entry.Path) {
+					status.locked = wt.Locked
+				}
+			}
+		}
+		if previous != nil && time.Since(previous.sizedAt) < uiSizeInterval {
+			status.size, status.sizedAt = previous.size, previous.sizedAt
+		} else {
+			status.size, status.sizedAt = dirSize(entry.Path), time.Now()
+		}
+	}
+
+	d.mu.Lock()
+	d.status[entry.ID] = status
+	d.mu.Unlock()
+	d.requestRedraw()
+}
+
+// requestRedraw asks the main loop to draw the screen again
+func (d *dashboard) requestRedraw() {
+	select {
+	case d.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// selected returns the worktree under the cursor
+func (d *dashboard) selected() (state.WorktreeEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.entries) == 0 {
+		return state.WorktreeEntry{}, false
+	}
+	return d.entries[d.cursor], true
+}
+
+// draw renders the whole screen
+func (d *dashboard) draw() {
+	width, height, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	t := newTable("REPO", "BRANCH", "STATUS", "UPSTREAM", "LOCK", "SIZE", "AGE")
+	for _, entry := range d.entries {
+		cells := []string{"…", "…", "", "…"}
+		if status := d.status[entry.ID]; status != nil && status.loaded {
+			cells = statusCells(status)
+		}
+		t.addRow(append([]string{entry.GitRepo, entry.BranchName}, append(cells, formatAge(time.Since(entry.CreatedAt)))...)...)
+	}
+	var b strings.Builder
+	t.render(&b, nil, width-2)
+	rows := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+
+	title := fmt.Sprintf(" Worktrees (%d)", len(d.entries))
+	if d.refreshing {
+		title += "  refreshing…"
+	} else if !d.refreshed.IsZero() {
+		title += "  refreshed " + d.refreshed.Format("15:04:05")
+	}
+	lines := []string{"\x1b[7m" + pad(truncate(title, width), width) + "\x1b[0m", "\x1b[2m  " + rows[0] + "\x1b[0m"}
+
+	listHeight := height - 4 // Title, header, message and key help
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+listHeight {
+		d.offset = d.cursor - listHeight + 1
+	}
+	for i := d.offset; i < d.offset+listHeight; i++ {
+		switch {
+		case len(d.entries) == 0 && i == 0:
+			lines = append(lines, "  No managed worktrees; press c to create one in the current repository")
+		case i >= len(d.entries):
+			lines = append(lines, "")
+		case i == d.cursor:
+			lines = append(lines, "\x1b[7m"+pad(truncate("  "+rows[i+1], width), width)+"\x1b[0m")
+		default:
+			lines = append(lines, truncate("  "+rows[i+1], width))
+		}
+	}
+
+	footer := d.message
+	if d.prompt != nil {
+		footer = d.prompt.question + " " + string(d.prompt.value)
+		if !d.prompt.line {
+			footer = d.prompt.question + " [y/N]"
+		}
+	}
+	lines = append(lines, truncate(footer, width))
+	lines = append(lines, "\x1b[2m"+truncate("enter switch  c create  d remove  l lock  p prune  e editor  x exec  r refresh  q quit", width)+"\x1b[0m")
+
+	frame := "\x1b[H\x1b[2J" + strings.Join(lines, "\r\n")
+	if d.prompt != nil && d.prompt.line {
+		frame += fmt.Sprintf("\x1b[%d;%dH\x1b[?25h", len(lines)-1, utf8.RuneCountInString(footer)+1)
+	} else {
+		frame += "\x1b[?25l"
+	}
+	io.WriteString(d.out, frame)
+}
+
+// statusCells formats the STATUS, UPSTREAM, LOCK and SIZE columns
+func statusCells(status *uiStatus) []string {
+	if status.missing {
+		return []string{"missing", "-", "", "-"}
+	}
+	cells := []string{"clean", "-", "", formatBytes(status.size)}
+	if status.dirty {
+		cells[0] = "dirty"
+	}
+	if status.upstream {
+		cells[1] = fmt.Sprintf("↑%d ↓%d", status.ahead, status.behind)
+	}
+	if status.locked {
+		cells[2] = "locked"
+	}
+	return cells
+}
+
+// handle processes keys and reports whether the dashboard should close
+func (d *dashboard) handle(keys []byte) bool {
+	if d.prompt != nil {
+		d.handlePrompt(keys)
+		return false
+	}
+
+	switch {
+	case keys[0] == 27 && len(keys) >= 3 && (keys[1] == '[' || keys[1] == 'O'):
+		switch keys[2] {
+		case 'A':
+			d.move(-1)
+		case 'B':
+			d.move(1)
+		}
+		return false
+	case keys[0] == 27 || keys[0] == 3 || keys[0] == 'q':
+		return true
+	}
+
+	switch keys[0] {
+	case 'k', 16:
+		d.move(-1)
+	case 'j', 14:
+		d.move(1)
+	case 'r':
+		go d.refresh()
+	case '\r', 's':
+		if entry, ok := d.selected(); ok {
+			d.switchTo = &entry
+			return true
+		}
+	case 'c':
+		d.create()
+	case 'd':
+		d.remove()
+	case 'l':
+		d.toggleLock()
+	case 'p':
+		d.prune()
+	case 'e':
+		d.openEditor()
+	case 'x':
+		d.exec()
+	}
+	return false
+}
+
+// handlePrompt feeds keys to the open prompt
+func (d *dashboard) handlePrompt(keys []byte) {
+	p := d.prompt
+	if !p.line {
+		d.prompt = nil
+		if keys[0] == 'y' || keys[0] == 'Y' {
+			p.answer("y")
+		} else {
+			d.message = "Cancelled"
+		}
+		return
+	}
+
+	for len(keys) > 0 {
+		switch key := keys[0]; {
+		case key == 3 || key == 27:
+			d.prompt = nil
+			d.message = "Cancelled"
+			return
+		case key == '\r' || key == '\n':
+			d.prompt = nil
+			p.answer(strings.TrimSpace(string(p.value)))
+			return
+		case key == 127 || key == 8:
+			if len(p.value) > 0 {
+				p.value = p.value[:len(p.value)-1]
+			}
+		case key == 21: // Ctrl-U
+			p.value = nil
+		case key >= 32:
+			r, size := utf8.DecodeRune(keys)
+			p.value = append(p.value, r)
+			keys = keys[size:]
+			continue
+		}
+		keys = keys[1:]
+	}
+}
+
+// move moves the cursor by delta, stopping at either end of the list
+func (d *dashboard) move(delta int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cursor += delta
+	if d.cursor >= len(d.entries) {
+		d.cursor = len(d.entries) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+}
+
+// ask opens a prompt in the footer
+func (d *dashboard) ask(question string, line bool, answer func(string)) {
+	d.prompt = &uiPrompt{question: question, line: line, answer: answer}
+}
+
+// gitRootFor returns a checkout of the repository of entry to run git commands in
+func gitRootFor(entry state.WorktreeEntry) (string, error) {
+	return groupByRepo([]state.WorktreeEntry{entry})[0].checkoutDir()
+}
+
+// create asks for a branch name and creates a worktree for it in the
+// repository of the selected worktree, or of the current directory
+func (d *dashboard) create() {
+	gitRoot := ""
+	repo := "the current repository"
+	if entry, ok := d.selected(); ok {
+		var err error
+		if gitRoot, err = gitRootFor(entry); err != nil {
+			d.message = "Error: " + err.Error()
+			return
+		}
+		repo = entry.GitRepo
+	} else if root, err := gitOutput("", "rev-parse", "--show-toplevel"); err == nil {
+		gitRoot = root
+	} else {
+		d.message = "Error: not in a git repository"
+		return
+	}
+
+	d.ask("New worktree in "+repo+", branch:", true, func(branch string) {
+		if branch == "" {
+			d.message = "Cancelled"
+			return
+		}
+		newBranch := !branchExists(gitRoot, branch)
+		var err error
+		output := captureOutput(func() {
+			_, err = createWorktree(d.stateManager, gitRoot, branch, newBranch, nil)
+		})
+		switch {
+		case errors.Is(err, errQuotaExceeded):
+			d.message = lastLine(output)
+		case err != nil:
+			d.message = "Error: " + strings.Join(strings.Fields(err.Error()), " ")
+		case newBranch:
+			d.message = fmt.Sprintf("Created branch '%s' and its worktree", branch)
+		default:
+			d.message = fmt.Sprintf("Created worktree for '%s'", branch)
+		}
+		d.reload()
+		go d.refresh()
+	})
+}
+
+// branchExists reports whether branch exists locally or on a remote
+func branchExists(gitRoot, branch string) bool {
+	if _, err := gitOutput(gitRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return true
+	}
+	out, err := gitOutput(gitRoot, "for-each-ref", "--format=%(refname)", "refs/remotes/*/"+branch)
+	return err == nil && out != ""
+}
+
+// remove removes the selected worktree after the same checks as the remove command
+func (d *dashboard) remove() {
+	entry, ok := d.selected()
+	if !ok {
+		return
+	}
+	gitRoot, err := gitRootFor(entry)
+	if err != nil {
+		d.message = "Error: " + err.Error()
+		return
+	}
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		d.message = "Error loading configuration: " + err.Error()
+		return
+	}
+	// Without a complete check there is no telling what would be lost
+	report, err := checkWorktree(entry, gitRoot, cfg.ForRepo(entry.GitRepo).Precious)
+	if err != nil {
+		d.message = fmt.Sprintf("Not removing '%s': checking worktree: %v", entry.BranchName, err)
+		return
+	}
+	if blockers := report.blockers(false); len(blockers) > 0 {
+		d.message = fmt.Sprintf("Not removing '%s': %s would be lost", entry.BranchName, strings.Join(blockers, ", "))
+		return
+	}
+	procs, err := processesUsing(entry.Path)
+	if err != nil {
+		d.message = fmt.Sprintf("Not removing '%s': checking for processes: %v", entry.BranchName, err)
+		return
+	}
+	if len(procs) > 0 {
+		d.message = fmt.Sprintf("Not removing '%s': in use by %d process(es)", entry.BranchName, len(procs))
+		return
+	}
+
+	d.ask(fmt.Sprintf("Remove worktree '%s' (%s)?", entry.BranchName, entry.GitRepo), false, func(string) {
+		var err error
+		captureOutput(func() {
+			err = removeWorktree(d.stateManager, entry, gitRoot, removeOptions{})
+		})
+		if err != nil {
+			d.message = "Error: " + strings.Join(strings.Fields(err.Error()), " ")
+		} else {
+			d.message = fmt.Sprintf("Removed worktree '%s'", entry.BranchName)
+		}
+		d.reload()
+	})
+}
+
+// toggleLock locks the selected worktree, or unlocks it if it is locked
+func (d *dashboard) toggleLock() {
+	entry, ok := d.selected()
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	status := d.status[entry.ID]
+	d.mu.Unlock()
+	if status == nil || !status.loaded || status.missing {
+		return
+	}
+
+	args := []string{"worktree", "lock", "--reason", "locked from the git-worktree-manager dashboard", entry.Path}
+	done := "Locked"
+	if status.locked {
+		args = []string{"worktree", "unlock", entry.Path}
+		done = "Unlocked"
+	}
+	if out, err := gitChange(entry.Path, args...); err != nil {
+		d.message = fmt.Sprintf("Error: %v: %s", err, strings.Join(strings.Fields(out), " "))
+		return
+	}
+	d.message = fmt.Sprintf("%s worktree '%s'", done, entry.BranchName)
+	go d.refreshEntry(entry)
+}
+
+// prune removes finished and expired worktrees of all repositories, like
+// the prune command without fetching first
+func (d *dashboard) prune() {
+	cfg, err := state.LoadConfig()
+	if err != nil {
+		d.message = "Error loading configuration: " + err.Error()
+		return
+	}
+	d.mu.Lock()
+	entries := append([]state.WorktreeEntry(nil), d.entries...)
+	d.mu.Unlock()
+
+	var candidates []pruneCandidate
+	for _, group := range groupByRepo(entries) {
+		ttl, err := repoTTL(cfg, group.repo)
+		if err != nil {
+			continue
+		}
+		found, err := findPruneCandidates(group, ttl, false, false, cfg.ForRepo(group.repo))
+		if err != nil {
+			continue
+		}
+		for _, candidate := range found {
+			if candidate.skip == "" {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		d.message = "Nothing to prune"
+		return
+	}
+
+	branches := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		branches[i] = candidate.entry.BranchName
+	}
+	d.ask(fmt.Sprintf("Prune %d worktree(s): %s?", len(candidates), strings.Join(branches, ", ")), false, func(string) {
+		removed := 0
+		captureOutput(func() {
+			for _, candidate := range candidates {
+				opts := removeOptions{forceBranch: candidate.merged}
+				if removeWorktree(d.stateManager, candidate.entry, candidate.gitRoot, opts) == nil {
+					removed++
+				}
+			}
+		})
+		d.message = fmt.Sprintf("Pruned %d of %d worktree(s)", removed, len(candidates))
+		d.reload()
+	})
+}
+
+// openEditor opens the selected worktree in the user's editor
+func (d *dashboard) openEditor() {
+	entry, ok := d.selected()
+	if !ok {
+		return
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		d.message = "Set $VISUAL or $EDITOR to open worktrees in an editor"
+		return
+	}
+
+	args := append(strings.Fields(editor), entry.Path)
+	d.suspend(func() {
+		command := exec.Command(args[0], args[1:]...)
+		command.Dir = entry.Path
+		command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := command.Run(); err != nil {
+			d.message = fmt.Sprintf("Error running %s: %v", args[0], err)
+		} else {
+			d.message = ""
+		}
+	})
+	go d.refreshEntry(entry)
+}
+
+// exec asks for a command and runs it in the selected worktree like the exec command
+func (d *dashboard) exec() {
+	entry, ok := d.selected()
+	if !ok {
+		return
+	}
+	d.ask(fmt.Sprintf("Run in '%s':", entry.BranchName), true, func(command string) {
+		if command == "" {
+			d.message = "Cancelled"
+			return
+		}
+		args := []string{"sh", "-c", command}
+		if runtime.GOOS == "windows" {
+			args = []string{"cmd", "/C", command}
+		}
+		d.suspend(func() {
+			result := runInWorktrees([]state.WorktreeEntry{entry}, args)[0]
+			switch {
+			case result.err != nil:
+				d.message = fmt.Sprintf("Error: %v", result.err)
+			default:
+				d.message = fmt.Sprintf("'%s' exited with status %d", command, result.exitCode)
+			}
+			fmt.Printf("\n%s. Press Enter to return to the dashboard.", d.message)
+			bufio.NewReader(os.Stdin).ReadString('\n')
+		})
+		go d.refreshEntry(entry)
+	})
+}
+
+// suspend leaves the dashboard's screen while run uses the terminal
+func (d *dashboard) suspend(run func()) {
+	fmt.Fprint(d.out, "\x1b[H\x1b[2J\x1b[?25h\x1b[?1049l")
+	term.Restore(d.in, d.rawState)
+	run()
+	if rawState, err := term.MakeRaw(d.in); err == nil {
+		d.rawState = rawState
+	}
+	fmt.Fprint(d.out, "\x1b[?1049h\x1b[?25l")
+}
+
+// captureOutput runs fn with stdout redirected and returns what it printed,
+// so that messages of the commands' building blocks do not garble the screen
+func captureOutput(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	var buf bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&buf, r)
+		close(copied)
+	}()
+
+	fn()
+	w.Close()
+	os.Stdout = stdout
+	<-copied
+	r.Close()
+	return buf.String()
+}
+
+// lastLine returns the last non-empty line of output
+func lastLine(output string) string {
+	lines := splitLines(strings.TrimSpace(output))
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
+
+// samePath reports whether a and b name the same directory
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
@@ -0,0 +1,112 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// RepoConfig holds settings that can be set globally and overridden per repository
+type RepoConfig struct {
+	TTL          string   `json:"ttl,omitempty"`           // Expire worktrees not accessed for this long (e.g. "30d")
+	MaxWorktrees int      `json:"max_worktrees,omitempty"` // Quota on the number of worktrees
+	MaxSizeGB    float64  `json:"max_size_gb,omitempty"`   // Quota on the total size of all worktrees
+	QuotaMode    string   `json:"quota_mode,omitempty"`    // "warn" (default) or "block" when a quota is exceeded
+	Precious     []string `json:"precious,omitempty"`      // Patterns of ignored files that must not be removed silently
+	SnapshotKeep int      `json:"snapshot_keep,omitempty"` // Number of snapshots kept per branch
+	SnapshotTTL  string   `json:"snapshot_ttl,omitempty"`  // Delete snapshots older than this (e.g. "30d")
+	Protected    []string `json:"protected,omitempty"`     // Branch patterns that are never deleted or pruned
+	PromptFormat string   `json:"prompt_format,omitempty"` // Template of the prompt segment printed by the prompt command
+}
+
+// Config holds user settings
+type Config struct {
+	Defaults RepoConfig            `json:"defaults"`
+	Repos    map[string]RepoConfig `json:"repos,omitempty"` // Key is the organization/repository name
+
+	path string
+}
+
+// LoadConfig reads the configuration file stored next to the state file.
+// A missing file yields an empty configuration.
+func LoadConfig() (*Config, error) {
+	statePath, err := getConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Repos: make(map[string]RepoConfig),
+		path:  filepath.Join(filepath.Dir(statePath), "config.json"),
+	}
+
+	data, err := os.ReadFile(cfg.path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Repos == nil {
+		cfg.Repos = make(map[string]RepoConfig)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration to disk
+func (c *Config) Save() error {
+	if dryRun != nil {
+		dryRun("write", c.path, "configuration")
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Path returns the location of the configuration file
+func (c *Config) Path() string {
+	return c.path
+}
+
+// ForRepo returns the effective settings for a repository: its own values
+// where set, the defaults otherwise
+func (c *Config) ForRepo(gitRepo string) RepoConfig {
+	effective := c.Defaults
+	repo, exists := c.Repos[gitRepo]
+	if !exists {
+		return effective
+	}
+	if repo.TTL != "" {
+		effective.TTL = repo.TTL
+	}
+	if repo.MaxWorktrees != 0 {
+		effective.MaxWorktrees = repo.MaxWorktrees
+	}
+	if repo.MaxSizeGB != 0 {
+		effective.MaxSizeGB = repo.MaxSizeGB
+	}
+	if repo.QuotaMode != "" {
+		effective.QuotaMode = repo.QuotaMode
+	}
+	if len(repo.Precious) > 0 {
+		effective.Precious = repo.Precious
+	}
+	if repo.SnapshotKeep != 0 {
+		effective.SnapshotKeep = repo.SnapshotKeep
+	}
+	if repo.SnapshotTTL != "" {
+		effective.SnapshotTTL = repo.SnapshotTTL
+	}
+	if len(repo.Protected) > 0 {
+		effective.Protected = repo.Protected
+	}
+	if repo.PromptFormat != "" {
+		effective.PromptFormat = repo.PromptFormat
+	}
+	return effective
+}
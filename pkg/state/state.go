@@ -6,30 +6,70 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"sync"
 	"time"
 )
 
 // WorktreeEntry represents a single worktree registration
 type WorktreeEntry struct {
-	ID           string    `json:"id"`            // Unique identifier (orgRepo/branchName)
-	Path         string    `json:"path"`          // Full path to the worktree
-	GitRepo      string    `json:"git_repo"`      // Organization/repository name (e.g., "owner/repo")
-	BranchName   string    `json:"branch_name"`   // Branch name
-	RemoteURL    string    `json:"remote_url"`    // Git remote URL
-	CreatedAt    time.Time `json:"created_at"`    // When the worktree was created
-	LastAccessed time.Time `json:"last_accessed"` // When the worktree was last accessed
+	ID           string    `json:"id"`                  // Unique identifier (orgRepo/branchName)
+	Path         string    `json:"path"`                // Full path to the worktree
+	GitRepo      string    `json:"git_repo"`            // Organization/repository name (e.g., "owner/repo")
+	BranchName   string    `json:"branch_name"`         // Branch name
+	RemoteURL    string    `json:"remote_url"`          // Git remote URL
+	CreatedAt    time.Time `json:"created_at"`          // When the worktree was created
+	LastAccessed time.Time `json:"last_accessed"`       // When the worktree was last accessed
+	Labels       []string  `json:"labels,omitempty"`    // Free-form labels used for filtering
+	RepoPath     string    `json:"repo_path,omitempty"` // Main checkout of the repository, if known
+	Pinned       bool      `json:"pinned,omitempty"`    // Pinned worktrees never expire
+	Aliases      []string  `json:"aliases,omitempty"`   // Nicknames the worktree can be referred to by
+}
+
+// HasLabel reports whether the entry carries the given label
+func (e WorktreeEntry) HasLabel(label string) bool {
+	for _, l := range e.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAlias reports whether the entry can be referred to by the given nickname
+func (e WorktreeEntry) HasAlias(alias string) bool {
+	for _, a := range e.Aliases {
+		if a == alias {
+			return true
+		}
+	}
+	return false
 }
 
 // State represents the persistent state of the application
 type State struct {
 	Version   string                   `json:"version"`
-	Worktrees map[string]WorktreeEntry `json:"worktrees"` // Key is the ID (orgRepo/branchName)
+	Worktrees map[string]WorktreeEntry `json:"worktrees"`          // Key is the ID (orgRepo/branchName)
+	Previous  map[string]string        `json:"previous,omitempty"` // Directory left by the last switch, by orgRepo ("" for any repository)
+}
+
+// dryRun receives the changes that would be written while dry-run mode is on
+var dryRun func(action, target, detail string)
+
+// SetDryRun turns on dry-run mode: state managers and configurations keep
+// their changes in memory and report each one to record instead of writing
+// anything to disk. Passing nil turns dry-run mode off again.
+func SetDryRun(record func(action, target, detail string)) {
+	dryRun = record
 }
 
-// StateManager handles loading and saving of persistent state
+// StateManager handles loading and saving of persistent state.
+// It is safe for concurrent use.
 type StateManager struct {
 	configPath string
 	state      *State
+	pathIndex  map[string]string // Worktree path to ID, built on first use by WorktreeAt
+	mu         sync.Mutex
 }
 
 // NewStateManager creates a new state manager
@@ -55,6 +95,10 @@ func NewStateManager() (*StateManager, error) {
 	return sm, nil
 }
 
+// unmigratedPath is the old state file that dry-run mode reads because it
+// would have been migrated
+var unmigratedPath string
+
 // getConfigPath returns the path to the configuration file
 func getConfigPath() (string, error) {
 	var newDir, oldDir string
@@ -83,14 +127,25 @@ func getConfigPath() (string, error) {
 		oldDir = filepath.Join(homeDir, ".config", "git-worktree-manager")
 	}
 
+	newPath := filepath.Join(newDir, "state.json")
+	oldPath := filepath.Join(oldDir, "state.json")
+
+	// In dry-run mode nothing is written: the old file is read where it is
+	if dryRun != nil {
+		if _, errOld := os.Stat(oldPath); errOld == nil && unmigratedPath == "" {
+			if _, errNew := os.Stat(newPath); os.IsNotExist(errNew) {
+				unmigratedPath = oldPath
+				dryRun("migrate", oldPath, "to "+newPath)
+			}
+		}
+		return newPath, nil
+	}
+
 	// Ensure new directory exists
 	if err := os.MkdirAll(newDir, 0755); err != nil {
 		return "", err
 	}
 
-	newPath := filepath.Join(newDir, "state.json")
-	oldPath := filepath.Join(oldDir, "state.json")
-
 	// Migration logic: if old file exists and new file does not, move it
 	if _, errOld := os.Stat(oldPath); errOld == nil {
 		if _, errNew := os.Stat(newPath); os.IsNotExist(errNew) {
@@ -108,12 +163,16 @@ func getConfigPath() (string, error) {
 
 // load reads the state from disk
 func (sm *StateManager) load() error {
-	if _, err := os.Stat(sm.configPath); os.IsNotExist(err) {
+	path := sm.configPath
+	if dryRun != nil && unmigratedPath != "" {
+		path = unmigratedPath
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
 		// File doesn't exist, use default state
 		return nil
 	}
 
-	data, err := os.ReadFile(sm.configPath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
@@ -121,6 +180,16 @@ func (sm *StateManager) load() error {
 	return json.Unmarshal(data, sm.state)
 }
 
+// commit saves the state after a change, or only reports the change in dry-run mode
+func (sm *StateManager) commit(action, id, detail string) error {
+	sm.pathIndex = nil // Paths may have changed
+	if dryRun != nil {
+		dryRun(action, id, detail)
+		return nil
+	}
+	return sm.save()
+}
+
 // save writes the state to disk
 func (sm *StateManager) save() error {
 	data, err := json.MarshalIndent(sm.state, "", "  ")
@@ -131,8 +200,10 @@ func (sm *StateManager) save() error {
 	return os.WriteFile(sm.configPath, data, 0644)
 }
 
-// AddWorktree registers a new worktree
-func (sm *StateManager) AddWorktree(path, gitRepo, branchName, remoteURL string) error {
+// AddWorktree registers a new worktree. repoPath is the repository's main checkout and may be empty.
+func (sm *StateManager) AddWorktree(path, gitRepo, branchName, remoteURL, repoPath string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	id := filepath.Join(gitRepo, branchName)
 
 	entry := WorktreeEntry{
@@ -141,26 +212,77 @@ func (sm *StateManager) AddWorktree(path, gitRepo, branchName, remoteURL string)
 		GitRepo:      gitRepo,
 		BranchName:   branchName,
 		RemoteURL:    remoteURL,
+		RepoPath:     repoPath,
 		CreatedAt:    time.Now(),
 		LastAccessed: time.Now(),
 	}
 
 	sm.state.Worktrees[id] = entry
-	return sm.save()
+	return sm.commit("add", id, path)
 }
 
 // RemoveWorktree unregisters a worktree
 func (sm *StateManager) RemoveWorktree(gitRepo, branchName string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	id := filepath.Join(gitRepo, branchName)
 	delete(sm.state.Worktrees, id)
-	return sm.save()
+	return sm.commit("remove", id, "")
+}
+
+// UpdateWorktree applies update to a registered worktree and saves the state.
+// If update changes the repository or branch, the entry is re-registered under its new ID.
+func (sm *StateManager) UpdateWorktree(gitRepo, branchName string, update func(*WorktreeEntry)) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	id := filepath.Join(gitRepo, branchName)
+	entry, exists := sm.state.Worktrees[id]
+	if !exists {
+		return fmt.Errorf("worktree %s not registered", id)
+	}
+	update(&entry)
+
+	entry.ID = filepath.Join(entry.GitRepo, entry.BranchName)
+	detail := ""
+	if entry.ID != id {
+		if _, taken := sm.state.Worktrees[entry.ID]; taken {
+			return fmt.Errorf("worktree %s already registered", entry.ID)
+		}
+		delete(sm.state.Worktrees, id)
+		detail = "now " + entry.ID
+	}
+	sm.state.Worktrees[entry.ID] = entry
+	return sm.commit("update", id, detail)
+}
+
+// SetLabels replaces the labels of a registered worktree
+func (sm *StateManager) SetLabels(gitRepo, branchName string, labels []string) error {
+	return sm.UpdateWorktree(gitRepo, branchName, func(entry *WorktreeEntry) {
+		entry.Labels = labels
+	})
+}
+
+// SetPinned pins or unpins a registered worktree
+func (sm *StateManager) SetPinned(gitRepo, branchName string, pinned bool) error {
+	return sm.UpdateWorktree(gitRepo, branchName, func(entry *WorktreeEntry) {
+		entry.Pinned = pinned
+	})
+}
+
+// SetAliases replaces the nicknames of a registered worktree
+func (sm *StateManager) SetAliases(gitRepo, branchName string, aliases []string) error {
+	return sm.UpdateWorktree(gitRepo, branchName, func(entry *WorktreeEntry) {
+		entry.Aliases = aliases
+	})
 }
 
 // GetWorktree retrieves a worktree by git repo and branch name
 func (sm *StateManager) GetWorktree(gitRepo, branchName string) (WorktreeEntry, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	id := filepath.Join(gitRepo, branchName)
 	entry, exists := sm.state.Worktrees[id]
-	if exists {
+	if exists && dryRun == nil {
 		// Update last accessed time
 		entry.LastAccessed = time.Now()
 		sm.state.Worktrees[id] = entry
@@ -169,8 +291,34 @@ func (sm *StateManager) GetWorktree(gitRepo, branchName string) (WorktreeEntry,
 	return entry, exists
 }
 
+// SetPrevious records dir as the worktree left by a switch, both for gitRepo
+// and across repositories
+func (sm *StateManager) SetPrevious(gitRepo, dir string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.state.Previous == nil {
+		sm.state.Previous = make(map[string]string)
+	}
+	sm.state.Previous[""] = dir
+	if gitRepo != "" {
+		sm.state.Previous[gitRepo] = dir
+	}
+	return sm.commit("previous", gitRepo, dir)
+}
+
+// Previous returns the worktree left by the last switch within gitRepo, or
+// across repositories if gitRepo is empty
+func (sm *StateManager) Previous(gitRepo string) (string, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	dir, ok := sm.state.Previous[gitRepo]
+	return dir, ok
+}
+
 // ListWorktrees returns all registered worktrees
 func (sm *StateManager) ListWorktrees() []WorktreeEntry {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	worktrees := make([]WorktreeEntry, 0, len(sm.state.Worktrees))
 	for _, entry := range sm.state.Worktrees {
 		worktrees = append(worktrees, entry)
@@ -178,8 +326,37 @@ func (sm *StateManager) ListWorktrees() []WorktreeEntry {
 	return worktrees
 }
 
+// WorktreeAt returns the registered worktree containing dir. It walks up from
+// dir looking each directory up in an index of the worktree paths, so the cost
+// depends on the depth of dir rather than the number of worktrees. The access
+// is not recorded.
+func (sm *StateManager) WorktreeAt(dir string) (WorktreeEntry, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.pathIndex == nil {
+		sm.pathIndex = make(map[string]string, len(sm.state.Worktrees))
+		for id, entry := range sm.state.Worktrees {
+			sm.pathIndex[filepath.Clean(entry.Path)] = id
+		}
+	}
+
+	dir = filepath.Clean(dir)
+	for {
+		if id, ok := sm.pathIndex[dir]; ok {
+			return sm.state.Worktrees[id], true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return WorktreeEntry{}, false
+		}
+		dir = parent
+	}
+}
+
 // ListWorktreesByRepo returns all worktrees for a specific git repository
 func (sm *StateManager) ListWorktreesByRepo(gitRepo string) []WorktreeEntry {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	worktrees := make([]WorktreeEntry, 0)
 	for _, entry := range sm.state.Worktrees {
 		if entry.GitRepo == gitRepo {
@@ -189,21 +366,48 @@ func (sm *StateManager) ListWorktreesByRepo(gitRepo string) []WorktreeEntry {
 	return worktrees
 }
 
-// CleanupStaleEntries removes entries for worktrees that no longer exist on disk
+// StaleEntries returns the entries of worktrees that no longer exist on disk,
+// pinned ones included
+func (sm *StateManager) StaleEntries() []WorktreeEntry {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	var stale []WorktreeEntry
+	for _, entry := range sm.state.Worktrees {
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			stale = append(stale, entry)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].ID < stale[j].ID })
+	return stale
+}
+
+// CleanupStaleEntries removes entries for worktrees that no longer exist on disk.
+// Pinned entries are kept.
 func (sm *StateManager) CleanupStaleEntries() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 	toRemove := make([]string, 0)
 
 	for id, entry := range sm.state.Worktrees {
+		if entry.Pinned {
+			continue
+		}
 		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
 			toRemove = append(toRemove, id)
 		}
 	}
 
+	if len(toRemove) > 0 {
+		sm.pathIndex = nil
+	}
 	for _, id := range toRemove {
 		delete(sm.state.Worktrees, id)
+		if dryRun != nil {
+			dryRun("remove", id, "stale")
+		}
 	}
 
-	if len(toRemove) > 0 {
+	if len(toRemove) > 0 && dryRun == nil {
 		return sm.save()
 	}
 